@@ -0,0 +1,66 @@
+package goplin
+
+import (
+	"context"
+)
+
+// API holds the operations of the Joplin Data API implemented by Client,
+// together with the lookups by name and the note helpers the commands are
+// built from. Programs can depend on it instead of Client to use a fake in
+// tests. The functions built on top of it, like the import and export of
+// notes, are only available on Client.
+type API interface {
+	Host() string
+	Port() int
+	CheckToken() error
+	Close() error
+
+	GetNote(id string, fields string) (Note, error)
+	GetNotes(ids []string, fields string) ([]Note, error)
+	GetAllNotes(fields string, orderBy string, orderDir string) ([]Note, error)
+	GetNotesInNotebook(id string, fields string, orderBy string, orderDir string) ([]Note, error)
+	GetNotesByTag(id string, orderBy string, orderDir string) ([]Note, error)
+	GetNotesByTags(ids []string, orderBy string, orderDir string) ([][]Note, error)
+	GetNoteTags(id string) ([]Tag, error)
+	GetNoteResources(id string, fields string) ([]Resource, error)
+	CreateNoteItem(note Note) (Note, error)
+	CreateNote(title string, format NoteFormat, body string, notebook string, tags []string, createMissing bool) (Note, error)
+	CreateNoteIn(note Note, notebook string, tags []string, createMissing bool) (Note, error)
+	CreateNoteWithTags(note Note, tags []Tag) (Note, error)
+	SetNoteTags(note Note, titles []string) error
+	AppendToNote(id string, text string, heading string) (Note, error)
+	PrependToNote(id string, text string, heading string) (Note, error)
+	UpdateNote(id string, props map[string]interface{}) (Note, error)
+	MoveNoteToNotebook(note Note, notebook string) error
+	DeleteNote(id string, permanent bool) error
+
+	GetNotebook(id string, fields string) (Notebook, error)
+	GetNotebooks(ids []string, fields string) ([]Notebook, error)
+	GetAllNotebooks(fields string, orderBy string, orderDir string) ([]Notebook, error)
+	CreateNotebook(notebook Notebook) (Notebook, error)
+
+	GetTag(id string, fields string) (Tag, error)
+	GetTags(ids []string, fields string) ([]Tag, error)
+	GetAllTags(orderBy string, orderDir string) ([]Tag, error)
+	CreateTag(tag Tag) (Tag, error)
+	AddTagToNote(tagID string, note Note) error
+	DeleteTagFromNote(tagID string, noteID string) error
+	DeleteTag(id string) error
+	DeleteTags(ids []string) error
+
+	GetResource(id string, fields string) (Resource, error)
+	GetResources(ids []string, fields string) ([]Resource, error)
+	GetAllResources(fields string, orderBy string, orderDir string) ([]Resource, error)
+	GetResourceFile(id string) ([]byte, error)
+	CreateResource(resource Resource, filename string, data []byte) (Resource, error)
+
+	Search(query string, queryType string, fields string) ([]Item, error)
+	FindNote(titleOrID string) (Note, error)
+	FindNotebook(nameOrID string) (Notebook, error)
+	FindTag(nameOrID string) (Tag, error)
+	FindOrCreateTag(title string) (Tag, error)
+
+	Do(ctx context.Context, method string, path string, query map[string]string, body interface{}, out interface{}) error
+}
+
+var _ API = (*Client)(nil)
@@ -0,0 +1,155 @@
+package goplin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// headingLevel returns the level of a Markdown ATX heading and its text, or
+// zero if the line is not a heading.
+func headingLevel(line string) (int, string) {
+	trimmed := strings.TrimSpace(line)
+	level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+
+	if level == 0 || level > 6 {
+		return 0, ""
+	}
+
+	if len(trimmed) > level && trimmed[level] != ' ' && trimmed[level] != '\t' {
+		return 0, ""
+	}
+
+	return level, strings.TrimSpace(strings.TrimRight(strings.TrimSpace(trimmed[level:]), "#"))
+}
+
+// findSection returns the line indexes of the heading and of the end of the
+// section. The heading may be given with or without its leading '#'s.
+func findSection(lines []string, heading string) (int, int, error) {
+	wantLevel, wantText := headingLevel(heading)
+	if wantLevel == 0 {
+		wantText = strings.TrimSpace(heading)
+	}
+
+	start, level := -1, 0
+	inCode := false
+
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+
+			continue
+		}
+
+		if inCode {
+			continue
+		}
+
+		l, text := headingLevel(line)
+		if l == 0 {
+			continue
+		}
+
+		if start >= 0 && l <= level {
+			return start, i, nil
+		}
+
+		if start < 0 && strings.EqualFold(text, wantText) && (wantLevel == 0 || l == wantLevel) {
+			start, level = i, l
+		}
+	}
+
+	if start < 0 {
+		return 0, 0, fmt.Errorf("could not find section '%s'", heading)
+	}
+
+	return start, len(lines), nil
+}
+
+// AppendText adds the text at the end of the body or, if a heading is given,
+// at the end of the section below this heading.
+func AppendText(body string, text string, heading string) (string, error) {
+	text = strings.TrimRight(text, "\n")
+
+	if len(heading) == 0 {
+		if len(strings.TrimSpace(body)) == 0 {
+			return text + "\n", nil
+		}
+
+		return strings.TrimRight(body, "\n") + "\n" + text + "\n", nil
+	}
+
+	lines := strings.Split(body, "\n")
+
+	start, end, err := findSection(lines, heading)
+	if err != nil {
+		return body, err
+	}
+
+	// Blank lines at the end of the section stay in front of the next one.
+	last := end
+
+	for last > start+1 && len(strings.TrimSpace(lines[last-1])) == 0 {
+		last--
+	}
+
+	result := append([]string{}, lines[:last]...)
+	result = append(result, text)
+	result = append(result, lines[last:]...)
+
+	return strings.Join(result, "\n"), nil
+}
+
+// PrependText adds the text at the beginning of the body or, if a heading
+// is given, directly below this heading.
+func PrependText(body string, text string, heading string) (string, error) {
+	text = strings.TrimRight(text, "\n")
+
+	if len(heading) == 0 {
+		return text + "\n" + body, nil
+	}
+
+	lines := strings.Split(body, "\n")
+
+	start, _, err := findSection(lines, heading)
+	if err != nil {
+		return body, err
+	}
+
+	result := append([]string{}, lines[:start+1]...)
+	result = append(result, text)
+	result = append(result, lines[start+1:]...)
+
+	return strings.Join(result, "\n"), nil
+}
+
+// AppendToNote appends the text to the note with the given ID. See AppendText
+// for the meaning of the heading.
+func (c *Client) AppendToNote(id string, text string, heading string) (Note, error) {
+	note, err := c.GetNote(id, "id,title,body")
+	if err != nil {
+		return note, err
+	}
+
+	body, err := AppendText(note.Body, text, heading)
+	if err != nil {
+		return note, err
+	}
+
+	return c.UpdateNote(id, map[string]interface{}{"body": body})
+}
+
+// PrependToNote prepends the text to the note with the given ID. See
+// PrependText for the meaning of the heading.
+func (c *Client) PrependToNote(id string, text string, heading string) (Note, error) {
+	note, err := c.GetNote(id, "id,title,body")
+	if err != nil {
+		return note, err
+	}
+
+	body, err := PrependText(note.Body, text, heading)
+	if err != nil {
+		return note, err
+	}
+
+	return c.UpdateNote(id, map[string]interface{}{"body": body})
+}
@@ -0,0 +1,178 @@
+package goplin
+
+import (
+	"testing"
+)
+
+func TestAppendText(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		text    string
+		heading string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "end of body",
+			body: "first\nsecond\n",
+			text: "third",
+			want: "first\nsecond\nthird\n",
+		},
+		{
+			name: "end of body without newline",
+			body: "first",
+			text: "second\n",
+			want: "first\nsecond\n",
+		},
+		{
+			name: "empty body",
+			body: "\n\n",
+			text: "text",
+			want: "text\n",
+		},
+		{
+			name:    "end of section",
+			body:    "# Title\n\n## Log\n- one\n\n## Other\ntext\n",
+			text:    "- two",
+			heading: "## Log",
+			want:    "# Title\n\n## Log\n- one\n- two\n\n## Other\ntext\n",
+		},
+		{
+			name:    "heading without hashes",
+			body:    "## Log\n- one\n## Other\n",
+			text:    "- two",
+			heading: "log",
+			want:    "## Log\n- one\n- two\n## Other\n",
+		},
+		{
+			name:    "last section",
+			body:    "# Title\n## Log\n- one\n",
+			text:    "- two",
+			heading: "## Log",
+			want:    "# Title\n## Log\n- one\n- two\n",
+		},
+		{
+			name:    "section with subsections",
+			body:    "## Log\n- one\n### Details\ndetail\n## Other\n",
+			text:    "- two",
+			heading: "## Log",
+			want:    "## Log\n- one\n### Details\ndetail\n- two\n## Other\n",
+		},
+		{
+			name:    "empty section",
+			body:    "## Log\n\n## Other\n",
+			text:    "- one",
+			heading: "## Log",
+			want:    "## Log\n- one\n\n## Other\n",
+		},
+		{
+			name:    "heading in code block is ignored",
+			body:    "```\n## Log\n```\n## Log\n- one\n",
+			text:    "- two",
+			heading: "## Log",
+			want:    "```\n## Log\n```\n## Log\n- one\n- two\n",
+		},
+		{
+			name:    "heading level must match",
+			body:    "### Log\n- one\n",
+			text:    "- two",
+			heading: "## Log",
+			wantErr: true,
+		},
+		{
+			name:    "missing section",
+			body:    "# Title\n",
+			text:    "text",
+			heading: "Log",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AppendText(tt.body, tt.text, tt.heading)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("AppendText() = %q, want error", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("AppendText: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("AppendText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrependText(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		text    string
+		heading string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "beginning of body",
+			body: "first\nsecond\n",
+			text: "zeroth",
+			want: "zeroth\nfirst\nsecond\n",
+		},
+		{
+			name: "empty body",
+			body: "",
+			text: "text\n",
+			want: "text\n",
+		},
+		{
+			name:    "below heading",
+			body:    "# Title\n## Log\n- one\n",
+			text:    "- zero",
+			heading: "## Log",
+			want:    "# Title\n## Log\n- zero\n- one\n",
+		},
+		{
+			name:    "heading with closing hashes",
+			body:    "## Log ##\n- one\n",
+			text:    "- zero",
+			heading: "Log",
+			want:    "## Log ##\n- zero\n- one\n",
+		},
+		{
+			name:    "missing section",
+			body:    "# Title\n",
+			text:    "text",
+			heading: "## Log",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PrependText(tt.body, tt.text, tt.heading)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("PrependText() = %q, want error", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("PrependText: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("PrependText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package goplin
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DefaultBulkWorkers is the number of requests the bulk functions send at
+// the same time.
+const DefaultBulkWorkers = 8
+
+// BulkError holds the errors of a bulk operation per ID.
+type BulkError struct {
+	// IDs lists the failed IDs in the order they were given.
+	IDs    []string
+	Errors map[string]error
+}
+
+func (e *BulkError) Error() string {
+	first := e.IDs[0]
+
+	if len(e.IDs) == 1 {
+		return fmt.Sprintf("%s: %v", first, e.Errors[first])
+	}
+
+	return fmt.Sprintf("%d of the requests failed, the first one for %s: %v", len(e.IDs), first, e.Errors[first])
+}
+
+// Err returns the error for the ID, if any. It can be called on a nil
+// BulkError.
+func (e *BulkError) Err(id string) error {
+	if e == nil {
+		return nil
+	}
+
+	return e.Errors[id]
+}
+
+// ForEachID calls fn for every ID with up to workers calls at the same time.
+// fn gets the index of the ID, so that it can store its result in order. The
+// errors are returned as BulkError.
+func ForEachID(ids []string, workers int, fn func(i int, id string) error) error {
+	if workers <= 0 {
+		workers = DefaultBulkWorkers
+	}
+
+	errs := make([]error, len(ids))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers && w < len(ids); w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				errs[i] = fn(i, ids[i])
+			}
+		}()
+	}
+
+	for i := range ids {
+		indexes <- i
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	var bulkErr *BulkError
+
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		if bulkErr == nil {
+			bulkErr = &BulkError{Errors: make(map[string]error)}
+		}
+
+		if _, ok := bulkErr.Errors[ids[i]]; !ok {
+			bulkErr.IDs = append(bulkErr.IDs, ids[i])
+			bulkErr.Errors[ids[i]] = err
+		}
+	}
+
+	if bulkErr == nil {
+		return nil
+	}
+
+	return bulkErr
+}
+
+// TagIDs returns the IDs of the tags, e.g. for GetNotesByTags.
+func TagIDs(tags []Tag) []string {
+	ids := make([]string, len(tags))
+
+	for i, tag := range tags {
+		ids[i] = tag.ID
+	}
+
+	return ids
+}
+
+// GetNotes fetches the notes with the IDs concurrently. The notes are
+// returned in the order of the IDs, with empty notes for the IDs which
+// failed. The error is a BulkError.
+func (c *Client) GetNotes(ids []string, fields string) ([]Note, error) {
+	notes := make([]Note, len(ids))
+
+	err := ForEachID(ids, c.bulkWorkers, func(i int, id string) error {
+		var err error
+
+		notes[i], err = c.GetNote(id, fields)
+
+		return err
+	})
+
+	return notes, err
+}
+
+// GetTags fetches the tags with the IDs concurrently, like GetNotes.
+func (c *Client) GetTags(ids []string, fields string) ([]Tag, error) {
+	tags := make([]Tag, len(ids))
+
+	err := ForEachID(ids, c.bulkWorkers, func(i int, id string) error {
+		var err error
+
+		tags[i], err = c.GetTag(id, fields)
+
+		return err
+	})
+
+	return tags, err
+}
+
+// GetNotebooks fetches the notebooks with the IDs concurrently, like
+// GetNotes.
+func (c *Client) GetNotebooks(ids []string, fields string) ([]Notebook, error) {
+	notebooks := make([]Notebook, len(ids))
+
+	err := ForEachID(ids, c.bulkWorkers, func(i int, id string) error {
+		var err error
+
+		notebooks[i], err = c.GetNotebook(id, fields)
+
+		return err
+	})
+
+	return notebooks, err
+}
+
+// GetResources fetches the resources with the IDs concurrently, like
+// GetNotes.
+func (c *Client) GetResources(ids []string, fields string) ([]Resource, error) {
+	resources := make([]Resource, len(ids))
+
+	err := ForEachID(ids, c.bulkWorkers, func(i int, id string) error {
+		var err error
+
+		resources[i], err = c.GetResource(id, fields)
+
+		return err
+	})
+
+	return resources, err
+}
+
+// GetNotesByTags fetches the notes of the tags with the IDs concurrently.
+// The lists of notes are returned in the order of the tag IDs.
+func (c *Client) GetNotesByTags(ids []string, orderBy string, orderDir string) ([][]Note, error) {
+	notes := make([][]Note, len(ids))
+
+	err := ForEachID(ids, c.bulkWorkers, func(i int, id string) error {
+		var err error
+
+		notes[i], err = c.GetNotesByTag(id, orderBy, orderDir)
+
+		return err
+	})
+
+	return notes, err
+}
+
+// DeleteTags deletes the tags with the IDs concurrently.
+func (c *Client) DeleteTags(ids []string) error {
+	return ForEachID(ids, c.bulkWorkers, func(i int, id string) error {
+		return c.DeleteTag(id)
+	})
+}
@@ -0,0 +1,141 @@
+package goplin
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestForEachID(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name    string
+		ids     []string
+		workers int
+		fail    map[string]bool
+		wantIDs []string
+	}{
+		{
+			name:    "no IDs",
+			ids:     nil,
+			workers: 4,
+		},
+		{
+			name:    "all succeed",
+			ids:     []string{"a", "b", "c", "d", "e"},
+			workers: 2,
+		},
+		{
+			name:    "default number of workers",
+			ids:     []string{"a", "b", "c"},
+			workers: 0,
+		},
+		{
+			name:    "failed IDs in the given order",
+			ids:     []string{"a", "b", "c", "d", "e"},
+			workers: 3,
+			fail:    map[string]bool{"d": true, "b": true},
+			wantIDs: []string{"b", "d"},
+		},
+		{
+			name:    "duplicate IDs are reported once",
+			ids:     []string{"a", "b", "a"},
+			workers: 2,
+			fail:    map[string]bool{"a": true},
+			wantIDs: []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+
+			seen := make([]string, len(tt.ids))
+
+			err := ForEachID(tt.ids, tt.workers, func(i int, id string) error {
+				atomic.AddInt32(&calls, 1)
+				seen[i] = id
+
+				if tt.fail[id] {
+					return fmt.Errorf("%s: %w", id, errFailed)
+				}
+
+				return nil
+			})
+
+			if int(calls) != len(tt.ids) {
+				t.Errorf("fn called %d times, want %d", calls, len(tt.ids))
+			}
+
+			if len(tt.ids) != 0 && !reflect.DeepEqual(seen, tt.ids) {
+				t.Errorf("fn got IDs %v at their indexes, want %v", seen, tt.ids)
+			}
+
+			if len(tt.wantIDs) == 0 {
+				if err != nil {
+					t.Fatalf("ForEachID() = %v, want nil", err)
+				}
+
+				return
+			}
+
+			var bulkErr *BulkError
+
+			if !errors.As(err, &bulkErr) {
+				t.Fatalf("ForEachID() = %v, want a BulkError", err)
+			}
+
+			if !reflect.DeepEqual(bulkErr.IDs, tt.wantIDs) {
+				t.Errorf("IDs = %v, want %v", bulkErr.IDs, tt.wantIDs)
+			}
+
+			for _, id := range tt.ids {
+				if got := bulkErr.Err(id); (got != nil) != tt.fail[id] || (got != nil && !errors.Is(got, errFailed)) {
+					t.Errorf("Err(%q) = %v", id, got)
+				}
+			}
+		})
+	}
+}
+
+func TestBulkError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *BulkError
+		want string
+	}{
+		{
+			name: "one failed ID",
+			err: &BulkError{
+				IDs:    []string{"a"},
+				Errors: map[string]error{"a": errors.New("not found")},
+			},
+			want: "a: not found",
+		},
+		{
+			name: "several failed IDs",
+			err: &BulkError{
+				IDs:    []string{"b", "a"},
+				Errors: map[string]error{"a": errors.New("timeout"), "b": errors.New("not found")},
+			},
+			want: "2 of the requests failed, the first one for b: not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	var nilErr *BulkError
+
+	if err := nilErr.Err("a"); err != nil {
+		t.Errorf("Err on a nil BulkError = %v, want nil", err)
+	}
+}
@@ -0,0 +1,80 @@
+package goplin
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxCaptureTitleLength is the maximum length in characters of a title taken
+// from captured text.
+const MaxCaptureTitleLength = 80
+
+// ParseCapture splits captured text into title, body and tags. The title is
+// the first Markdown heading or, if there is none, the first non-empty line.
+// If the title line is the first line of the text, it is removed from the
+// body. The tags are the inline '#tag' tokens, which are removed from the
+// title but kept in the body.
+func ParseCapture(text string) (string, string, []string) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+
+	first, titleLine := -1, -1
+	inCode := false
+
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+		}
+
+		if first < 0 && len(strings.TrimSpace(line)) != 0 {
+			first = i
+		}
+
+		if level, _ := headingLevel(line); level != 0 && !inCode {
+			titleLine = i
+
+			break
+		}
+	}
+
+	if titleLine < 0 {
+		titleLine = first
+	}
+
+	if titleLine < 0 {
+		return "", "", nil
+	}
+
+	tags := InlineTags(text)
+
+	title := lines[titleLine]
+	if level, heading := headingLevel(title); level != 0 {
+		title = heading
+	}
+
+	title = inlineTagRegexp.ReplaceAllStringFunc(title, func(m string) string {
+		if len(InlineTags(m)) == 0 {
+			return m
+		}
+
+		return " "
+	})
+	title = strings.Join(strings.Fields(title), " ")
+
+	if utf8.RuneCountInString(title) > MaxCaptureTitleLength {
+		title = strings.TrimSpace(string([]rune(title)[:MaxCaptureTitleLength-1])) + "…"
+	}
+
+	body := text
+
+	if titleLine == first {
+		body = strings.Join(lines[titleLine+1:], "\n")
+	}
+
+	body = strings.Trim(body, "\n")
+	if len(body) != 0 {
+		body += "\n"
+	}
+
+	return title, body, tags
+}
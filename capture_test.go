@@ -0,0 +1,104 @@
+package goplin
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCapture(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantTitle string
+		wantBody  string
+		wantTags  []string
+	}{
+		{
+			name:      "empty",
+			text:      "\n  \n",
+			wantTitle: "",
+			wantBody:  "",
+		},
+		{
+			name:      "first line as title",
+			text:      "Buy milk\nand bread\n",
+			wantTitle: "Buy milk",
+			wantBody:  "and bread\n",
+		},
+		{
+			name:      "single line",
+			text:      "Call Bob",
+			wantTitle: "Call Bob",
+			wantBody:  "",
+		},
+		{
+			name:      "leading blank lines",
+			text:      "\n\nTitle\n\nBody\n",
+			wantTitle: "Title",
+			wantBody:  "Body\n",
+		},
+		{
+			name:      "heading as first line",
+			text:      "# Meeting notes\n\n- one\n- two\n",
+			wantTitle: "Meeting notes",
+			wantBody:  "- one\n- two\n",
+		},
+		{
+			name:      "heading below text is kept in the body",
+			text:      "Some intro\n## Heading\ntext\n",
+			wantTitle: "Heading",
+			wantBody:  "Some intro\n## Heading\ntext\n",
+		},
+		{
+			name:      "heading in code block is ignored",
+			text:      "```\n# comment\n```\n",
+			wantTitle: "```",
+			wantBody:  "# comment\n```\n",
+		},
+		{
+			name:      "tags are removed from the title",
+			text:      "Fix the build #work #urgent\ndetails #later\n",
+			wantTitle: "Fix the build",
+			wantBody:  "details #later\n",
+			wantTags:  []string{"work", "urgent", "later"},
+		},
+		{
+			name:      "duplicate and numeric tags",
+			text:      "Issue #42 #Bug\nsee #bug\n",
+			wantTitle: "Issue #42",
+			wantBody:  "see #bug\n",
+			wantTags:  []string{"Bug"},
+		},
+		{
+			name:      "CRLF line endings",
+			text:      "Title\r\nBody\r\n",
+			wantTitle: "Title",
+			wantBody:  "Body\n",
+		},
+		{
+			name:      "long title is truncated",
+			text:      strings.Repeat("a", MaxCaptureTitleLength+10) + "\nbody",
+			wantTitle: strings.Repeat("a", MaxCaptureTitleLength-1) + "…",
+			wantBody:  "body\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, body, tags := ParseCapture(tt.text)
+
+			if title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", title, tt.wantTitle)
+			}
+
+			if body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+
+			if !reflect.DeepEqual(tags, tt.wantTags) {
+				t.Errorf("tags = %q, want %q", tags, tt.wantTags)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/piccobit/goplin"
+)
+
+type AppendCmd struct {
+	Under           string `help:"Append at the end of the section with the specified heading, e.g. '## Log'."`
+	Timestamp       bool   `help:"Prefix the text with the current time."`
+	TimestampFormat string `name:"timestamp-format" default:"2006-01-02 15:04" help:"Format of the timestamp (Go time layout)."`
+
+	Note string `arg name:"note" help:"ID or title of the note."`
+	Text string `arg name:"text" help:"Text to append. Prefixing the string with a '@' will read the text from the given file, '-' reads it from stdin."`
+}
+
+type PrependCmd struct {
+	Under           string `help:"Prepend directly below the specified heading, e.g. '## Log'."`
+	Timestamp       bool   `help:"Prefix the text with the current time."`
+	TimestampFormat string `name:"timestamp-format" default:"2006-01-02 15:04" help:"Format of the timestamp (Go time layout)."`
+
+	Note string `arg name:"note" help:"ID or title of the note."`
+	Text string `arg name:"text" help:"Text to prepend. Prefixing the string with a '@' will read the text from the given file, '-' reads it from stdin."`
+}
+
+func insertText(note string, text string, timestamp bool, timestampFormat string, prepend bool, heading string) error {
+	text, err := goplin.ExpandBody(text)
+	if err != nil {
+		return err
+	}
+
+	if len(strings.TrimSpace(text)) == 0 {
+		return fmt.Errorf("nothing to insert")
+	}
+
+	if timestamp {
+		text = time.Now().Format(timestampFormat) + " " + text
+	}
+
+	n, err := client.FindNote(note)
+	if err != nil {
+		return err
+	}
+
+	if prepend {
+		_, err = client.PrependToNote(n.ID, text, heading)
+	} else {
+		_, err = client.AppendToNote(n.ID, text, heading)
+	}
+
+	return err
+}
+
+func (cmd *AppendCmd) Run(ctx *Globals) error {
+	return insertText(cmd.Note, cmd.Text, cmd.Timestamp, cmd.TimestampFormat, false, cmd.Under)
+}
+
+func (cmd *PrependCmd) Run(ctx *Globals) error {
+	return insertText(cmd.Note, cmd.Text, cmd.Timestamp, cmd.TimestampFormat, true, cmd.Under)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/piccobit/goplin"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+var errNoTerminal = errors.New("no terminal")
+
+type AuthLoginCmd struct {
+	Wait  time.Duration `default:"2m" help:"How long to wait for access to be granted in the Joplin app."`
+	Store string        `enum:",plain,encrypted,helper" default:"" placeholder:"plain|encrypted|helper" help:"Store the token in plain text, encrypted with a passphrase or with the credential helper. Defaults to 'token_store' of the config."`
+}
+
+type AuthStatusCmd struct{}
+
+type AuthLogoutCmd struct{}
+
+type AuthSetTokenCmd struct {
+	Store string `enum:",plain,encrypted,helper" default:"" placeholder:"plain|encrypted|helper" help:"Store the token in plain text, encrypted with a passphrase or with the credential helper. Defaults to 'token_store' of the config."`
+
+	Token string `arg optional name:"token" help:"API token from the Web Clipper options of Joplin. Prompted for if not given."`
+}
+
+func (cmd *AuthLoginCmd) offline()    {}
+func (cmd *AuthStatusCmd) offline()   {}
+func (cmd *AuthLogoutCmd) offline()   {}
+func (cmd *AuthSetTokenCmd) offline() {}
+
+func (cmd *AuthLoginCmd) createsProfile()    {}
+func (cmd *AuthSetTokenCmd) createsProfile() {}
+
+func profileName() string {
+	if len(profile) == 0 {
+		return "default"
+	}
+
+	return profile
+}
+
+// readSecret asks on the terminal and reads the answer without echoing it.
+// stdin is left alone, it may carry the input of the command.
+func readSecret(question string) (string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", errNoTerminal
+	}
+	defer tty.Close()
+
+	fmt.Fprint(tty, question)
+	input, err := term.ReadPassword(int(tty.Fd()))
+	fmt.Fprintln(tty)
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(input)), nil
+}
+
+// storeToken stores the token in the given store, which is remembered for
+// the profile, or in the configured one.
+func storeToken(ctx *Globals, store string, token string) error {
+	if len(store) != 0 {
+		viper.Set(profileKey("token_store"), store)
+	}
+
+	return saveToken(ctx, token)
+}
+
+func (cmd *AuthLoginCmd) Run(ctx *Globals) error {
+	opts, err := connectionOptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	opts.APIToken = ""
+
+	c, err := goplin.NewWithOptions(opts)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	fmt.Printf("Please grant access in the Joplin app on %s:%d.\n", c.Host(), c.Port())
+
+	err = c.Authorize(cmd.Wait, func(remaining time.Duration) {
+		fmt.Printf("\rWaiting for access to be granted... %s ", remaining.Round(time.Second))
+	})
+	fmt.Println()
+
+	if err != nil {
+		return err
+	}
+
+	cachePort(ctx, opts, c.Port())
+
+	err = storeToken(ctx, cmd.Store, c.GetApiToken())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Logged in, the token has been stored in profile '%s'.\n", profileName())
+
+	return nil
+}
+
+func (cmd *AuthStatusCmd) Run(ctx *Globals) error {
+	fmt.Printf("Profile: %s\n", profileName())
+	fmt.Printf("Store:   %s\n", tokenStore())
+
+	opts, err := connectionOptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	opts, err = withStoredToken(opts)
+	if err != nil {
+		return err
+	}
+
+	if len(opts.APIToken) == 0 {
+		fmt.Println("Token:   none")
+
+		return fmt.Errorf("not logged in")
+	}
+
+	c, err := goplin.NewWithOptions(opts)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	fmt.Printf("Joplin:  %s:%d\n", c.Host(), c.Port())
+
+	err = c.CheckToken()
+	if errors.Is(err, goplin.ErrInvalidToken) {
+		fmt.Println("Token:   invalid")
+
+		return err
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Token:   valid")
+
+	return nil
+}
+
+func (cmd *AuthLogoutCmd) Run(ctx *Globals) error {
+	err := saveToken(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed the token from profile '%s'.\n", profileName())
+
+	return nil
+}
+
+func (cmd *AuthSetTokenCmd) Run(ctx *Globals) error {
+	token := cmd.Token
+
+	if len(token) == 0 {
+		var err error
+
+		token, err = readSecret("API token: ")
+		if errors.Is(err, errNoTerminal) {
+			return fmt.Errorf("no terminal to ask for the token, please give it as argument")
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(token) == 0 {
+		return fmt.Errorf("no token given")
+	}
+
+	err := storeToken(ctx, cmd.Store, token)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("The token has been stored in profile '%s'.\n", profileName())
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/piccobit/goplin"
+)
+
+const defaultCaptureNotebook = "Inbox"
+
+type CaptureCmd struct {
+	Title    string   `help:"Title of the note. Defaults to the first heading or line of the text."`
+	Notebook string   `help:"Notebook path to store the note in. Defaults to 'capture.notebook' of the config or 'Inbox'."`
+	Tags     []string `name:"tag" help:"Tags to attach in addition to the inline '#tags'."`
+	Output   string   `short:"o" enum:"text,id,link,json" default:"text" help:"Print the new note as text, id, link or json."`
+
+	Text []string `arg optional name:"text" help:"Text of the note. Read from stdin if not given."`
+}
+
+func (cmd *CaptureCmd) Run(ctx *Globals) error {
+	text := strings.Join(cmd.Text, " ")
+
+	if len(cmd.Text) == 0 {
+		var err error
+
+		text, err = goplin.ExpandBody("-")
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(strings.TrimSpace(text)) == 0 {
+		return fmt.Errorf("nothing to capture")
+	}
+
+	title, body, tags := goplin.ParseCapture(text)
+
+	if len(cmd.Title) != 0 {
+		title, body = cmd.Title, text
+	}
+
+	if len(title) == 0 {
+		title = "Captured " + time.Now().Format("2006-01-02 15:04")
+	}
+
+	notebookPath := cmd.Notebook
+	if len(notebookPath) == 0 {
+		notebookPath = configString("capture.notebook", defaultCaptureNotebook)
+	}
+
+	note, err := client.CreateNoteIn(goplin.Note{
+		Title: title,
+		Body:  body,
+	}, notebookPath, append(tags, cmd.Tags...), true)
+	if err != nil {
+		return err
+	}
+
+	return printCreatedNote(note, cmd.Output, "Captured note")
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/piccobit/goplin"
+	"github.com/spf13/viper"
+)
+
+// profile is the name of the active profile, if any.
+var profile string
+
+// defaultMaxConcurrentRequests keeps bulk commands from overloading the
+// Joplin app.
+const defaultMaxConcurrentRequests = 4
+
+// offlineCommand is implemented by commands which do not need a connection
+// to Joplin.
+type offlineCommand interface {
+	offline()
+}
+
+func configFilePath(ctx *Globals) (string, error) {
+	if len(ctx.Config) != 0 {
+		return ctx.Config, nil
+	}
+
+	userHomeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(userHomeDir, ".goplin"), nil
+}
+
+// profileCreator is implemented by commands which create the profile given
+// with --profile if it is not in the config file yet.
+type profileCreator interface {
+	createsProfile()
+}
+
+// loadConfig reads the config file. A missing config file is not an error,
+// an unknown profile is unless createProfile is set.
+func loadConfig(ctx *Globals, createProfile bool) error {
+	viper.SetConfigType("yaml")
+
+	configFile, err := configFilePath(ctx)
+	if err != nil {
+		return err
+	}
+
+	viper.SetConfigFile(configFile)
+
+	profile = ctx.Profile
+
+	if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		if len(profile) != 0 && !createProfile {
+			return fmt.Errorf("unknown profile '%s', there is no config file '%s'", profile, configFile)
+		}
+
+		return nil
+	}
+
+	err = viper.ReadInConfig()
+	if err != nil {
+		return fmt.Errorf("could not read config file '%s': %w", configFile, err)
+	}
+
+	if len(profile) == 0 {
+		profile = viper.GetString("default_profile")
+	}
+
+	if len(profile) != 0 && !createProfile && !viper.IsSet("profiles."+profile) {
+		return fmt.Errorf("unknown profile '%s', it is not defined in the config file '%s'", profile, configFile)
+	}
+
+	return nil
+}
+
+// profileKey returns the config key of the setting in the active profile.
+func profileKey(key string) string {
+	if len(profile) == 0 {
+		return key
+	}
+
+	return "profiles." + profile + "." + key
+}
+
+// configString returns the setting of the active profile. Settings missing
+// in the profile are taken from the top level of the config file, then the
+// default is used.
+func configString(key string, defaultValue string) string {
+	if viper.IsSet(profileKey(key)) {
+		return viper.GetString(profileKey(key))
+	}
+
+	if value := viper.GetString(key); len(value) != 0 {
+		return value
+	}
+
+	return defaultValue
+}
+
+// configInt returns the integer setting like configString.
+func configInt(key string, defaultValue int) int {
+	if viper.IsSet(profileKey(key)) {
+		return viper.GetInt(profileKey(key))
+	}
+
+	if viper.IsSet(key) {
+		return viper.GetInt(key)
+	}
+
+	return defaultValue
+}
+
+// configFloat returns the float setting like configString.
+func configFloat(key string, defaultValue float64) float64 {
+	if viper.IsSet(profileKey(key)) {
+		return viper.GetFloat64(profileKey(key))
+	}
+
+	if viper.IsSet(key) {
+		return viper.GetFloat64(key)
+	}
+
+	return defaultValue
+}
+
+func saveConfig(ctx *Globals) error {
+	configFile, err := configFilePath(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = viper.WriteConfigAs(configFile)
+	if err != nil {
+		return err
+	}
+
+	return os.Chmod(configFile, 0600)
+}
+
+// connectionOptions returns the connection settings. Flags and GOPLIN_*
+// environment variables take precedence over the config file. The connection
+// settings of a profile are not mixed with those at the top level, as they
+// belong to another Joplin. The token is only taken from the flag or the
+// environment, see withStoredToken.
+func connectionOptions(ctx *Globals) (goplin.Options, error) {
+	var err error
+
+	opts := goplin.Options{
+		Host:                  ctx.Host,
+		Port:                  ctx.Port,
+		APIToken:              ctx.Token,
+		DumpBodyLimit:         ctx.DebugBodyLimit,
+		Retries:               configInt("retries", goplin.DefaultRetries),
+		MaxConcurrentRequests: configInt("max_concurrent_requests", defaultMaxConcurrentRequests),
+		RateLimit:             configFloat("rate_limit", 0),
+	}
+
+	opts.RetryWait, err = time.ParseDuration(configString("retry_wait", goplin.DefaultRetryWait.String()))
+	if err != nil {
+		return opts, fmt.Errorf("invalid 'retry_wait' in the config file: %w", err)
+	}
+
+	if ctx.Debug {
+		opts.Debug = os.Stderr
+	}
+
+	if len(opts.Host) == 0 {
+		opts.Host = viper.GetString(profileKey("host"))
+	}
+
+	if opts.Port == 0 {
+		opts.Port = viper.GetInt(profileKey("port"))
+	}
+
+	opts.CachedPort = viper.GetInt(profileKey("last_port"))
+
+	return opts, nil
+}
+
+// withStoredToken reads the token from the token store unless it has been
+// given as flag or in the environment.
+func withStoredToken(opts goplin.Options) (goplin.Options, error) {
+	if len(opts.APIToken) != 0 {
+		return opts, nil
+	}
+
+	var err error
+
+	opts.APIToken, err = loadToken()
+
+	return opts, err
+}
+
+// connect creates the client.
+func connect(ctx *Globals) error {
+	var err error
+
+	opts, err := connectionOptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	opts, err = withStoredToken(opts)
+	if err != nil {
+		return err
+	}
+
+	if len(opts.APIToken) == 0 {
+		return fmt.Errorf("no API token found, please run 'goplin auth login' or 'goplin auth set-token'")
+	}
+
+	client, err = goplin.NewWithOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	cachePort(ctx, opts, client.Port())
+
+	return nil
+}
+
+// cachePort remembers the port found, so that it is tried first next time.
+// The config file is only updated if it exists and the port has changed. A
+// failed update is not an error, the port is searched for again next time.
+func cachePort(ctx *Globals, opts goplin.Options, port int) {
+	if opts.Port != 0 || opts.CachedPort == port {
+		return
+	}
+
+	configFile, err := configFilePath(ctx)
+	if err != nil {
+		return
+	}
+
+	if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		return
+	}
+
+	viper.Set(profileKey("last_port"), port)
+
+	err = saveConfig(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not remember port %d in the config file: %v\n", port, err)
+	}
+}
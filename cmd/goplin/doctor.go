@@ -0,0 +1,269 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/piccobit/goplin"
+)
+
+type DoctorCmd struct {
+	Timeout time.Duration `default:"1s" help:"Timeout for probing a port."`
+	Samples int           `default:"5" help:"Number of requests for measuring the latency, 0 skips the measurement."`
+}
+
+func (cmd *DoctorCmd) offline() {}
+
+// doctorReport prints the results of the checks and counts the problems.
+type doctorReport struct {
+	problems int
+}
+
+func (r *doctorReport) ok(format string, a ...interface{}) {
+	fmt.Printf("[ ok ] "+format+"\n", a...)
+}
+
+func (r *doctorReport) info(format string, a ...interface{}) {
+	fmt.Printf("[info] "+format+"\n", a...)
+}
+
+func (r *doctorReport) warn(format string, a ...interface{}) {
+	fmt.Printf("[warn] "+format+"\n", a...)
+}
+
+func (r *doctorReport) fail(format string, a ...interface{}) {
+	r.problems++
+	fmt.Printf("[fail] "+format+"\n", a...)
+}
+
+func (r *doctorReport) checkConfigFile(ctx *Globals) {
+	configFile, err := configFilePath(ctx)
+	if err != nil {
+		r.fail("Could not determine the config file: %v", err)
+
+		return
+	}
+
+	info, err := os.Stat(configFile)
+	if os.IsNotExist(err) {
+		r.info("No config file at %s.", configFile)
+
+		return
+	}
+
+	if err != nil {
+		r.fail("Could not read the config file: %v", err)
+
+		return
+	}
+
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		r.fail("The config file %s has the permissions %04o and may be read by other users, run 'chmod 600 %s'.", configFile, perm, configFile)
+	} else {
+		r.ok("Config file %s with permissions %04o.", configFile, perm)
+	}
+
+	r.info("Profile '%s', token store '%s'.", profileName(), tokenStore())
+}
+
+// checkPorts reports the programs listening on the ports of Joplin and
+// returns the port to connect to, or zero.
+func (r *doctorReport) checkPorts(host string, port int, timeout time.Duration) int {
+	statuses := goplin.ScanPorts(host, timeout)
+
+	if port != 0 {
+		inRange := false
+
+		for _, status := range statuses {
+			inRange = inRange || status.Port == port
+		}
+
+		if !inRange {
+			statuses = append(statuses, goplin.Ping(host, port, timeout))
+		}
+	}
+
+	var instances []int
+
+	for _, status := range statuses {
+		switch {
+		case status.Joplin:
+			instances = append(instances, status.Port)
+			r.ok("Joplin answers on %s:%d (%s).", host, status.Port, status.Latency.Round(time.Millisecond))
+		case status.Open && status.Err != nil:
+			r.warn("Port %d is used by another program: %v", status.Port, status.Err)
+		case status.Open:
+			r.warn("Port %d is used by another program, which answered '%s'.", status.Port, status.Response)
+		case status.Port == port:
+			r.fail("Nothing listens on the configured port %d: %v", port, status.Err)
+		}
+	}
+
+	if len(instances) == 0 {
+		r.fail("No Joplin Web Clipper service found on %s. Start Joplin and enable the service in Tools > Options > Web Clipper.", host)
+
+		return 0
+	}
+
+	if len(instances) > 1 {
+		r.warn("%d Joplin instances are running, make sure the port of each profile is set.", len(instances))
+	}
+
+	if port == 0 {
+		return instances[0]
+	}
+
+	for _, instance := range instances {
+		if instance == port {
+			return port
+		}
+	}
+
+	r.fail("The configured port %d does not belong to Joplin, which runs on port %d. Another program may have taken the port.", port, instances[0])
+
+	return 0
+}
+
+func (r *doctorReport) checkLatency(c *goplin.Client, samples int, timeout time.Duration) {
+	if samples <= 0 {
+		r.info("Skipped measuring the latency.")
+
+		return
+	}
+
+	var min, max, total time.Duration
+
+	n := 0
+
+	for i := 0; i < samples; i++ {
+		status := goplin.Ping(c.Host(), c.Port(), timeout)
+		if !status.Joplin {
+			continue
+		}
+
+		if n == 0 || status.Latency < min {
+			min = status.Latency
+		}
+
+		if status.Latency > max {
+			max = status.Latency
+		}
+
+		total += status.Latency
+		n++
+	}
+
+	if n == 0 {
+		r.fail("Joplin did not answer any of %d pings.", samples)
+
+		return
+	}
+
+	avg := total / time.Duration(n)
+	report := r.ok
+
+	if avg > 500*time.Millisecond {
+		report = r.warn
+	}
+
+	report("Latency of %d pings: min %s, avg %s, max %s.", n, min.Round(time.Microsecond), avg.Round(time.Microsecond), max.Round(time.Microsecond))
+}
+
+func (cmd *DoctorCmd) Run(ctx *Globals) error {
+	r := &doctorReport{}
+
+	r.checkConfigFile(ctx)
+
+	opts, err := connectionOptions(ctx)
+	if err != nil {
+		r.fail("Invalid connection settings: %v", err)
+	}
+
+	opts, err = withStoredToken(opts)
+	if err != nil {
+		r.fail("Could not read the token: %v", err)
+	}
+
+	if len(opts.Host) == 0 {
+		opts.Host = "localhost"
+	}
+
+	opts.Port = r.checkPorts(opts.Host, opts.Port, cmd.Timeout)
+	if opts.Port == 0 {
+		return fmt.Errorf("%d problem(s) found", r.problems)
+	}
+
+	c, err := goplin.NewWithOptions(opts)
+	if err != nil {
+		r.fail("Could not connect to Joplin: %v", err)
+
+		return fmt.Errorf("%d problem(s) found", r.problems)
+	}
+	defer c.Close()
+
+	r.checkLatency(c, cmd.Samples, cmd.Timeout)
+
+	if len(opts.APIToken) == 0 {
+		r.fail("No API token found, run 'goplin auth login' or 'goplin auth set-token'.")
+
+		return fmt.Errorf("%d problem(s) found", r.problems)
+	}
+
+	err = c.CheckToken()
+	if errors.Is(err, goplin.ErrInvalidToken) {
+		r.fail("Joplin rejects the API token, run 'goplin auth login' to get a new one.")
+
+		return fmt.Errorf("%d problem(s) found", r.problems)
+	}
+
+	if err != nil {
+		r.fail("Could not check the API token: %v", err)
+
+		return fmt.Errorf("%d problem(s) found", r.problems)
+	}
+
+	r.ok("The API token is valid.")
+
+	capabilities, err := c.Capabilities()
+	if err != nil {
+		r.fail("Could not probe the API: %v", err)
+	} else {
+		var supported, unsupported []string
+
+		for _, capability := range capabilities {
+			if capability.Supported {
+				supported = append(supported, capability.Endpoint)
+			} else {
+				unsupported = append(unsupported, capability.Endpoint)
+			}
+		}
+
+		r.info("Joplin does not report its version. Supported endpoints: %s.", strings.Join(supported, ", "))
+
+		if len(unsupported) != 0 {
+			r.info("Unsupported endpoints: %s.", strings.Join(unsupported, ", "))
+		}
+	}
+
+	start := time.Now()
+
+	hasNotes, err := c.HasNotes()
+	if err != nil {
+		r.fail("Could not list the notes: %v", err)
+	} else if !hasNotes {
+		r.warn("Joplin on port %d has no notes. Is it the right profile or instance?", c.Port())
+	} else {
+		r.ok("Listing notes works (%s).", time.Since(start).Round(time.Millisecond))
+	}
+
+	if r.problems != 0 {
+		return fmt.Errorf("%d problem(s) found", r.problems)
+	}
+
+	fmt.Println("No problems found.")
+
+	return nil
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/piccobit/goplin"
+)
+
+type EditCmd struct {
+	NoHeader bool `help:"Do not add a header with the title and the tags of the note."`
+
+	Note string `arg name:"note" help:"ID or title of the note to edit."`
+}
+
+const editHeaderDelimiter = "---"
+
+func editorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.Fields(os.Getenv(name)); len(editor) != 0 {
+			return editor
+		}
+	}
+
+	return []string{"vi"}
+}
+
+// editText lets the user edit the given text in $EDITOR and returns the
+// result.
+func editText(text string) (string, error) {
+	f, err := os.CreateTemp("", "goplin-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(text)
+	if err != nil {
+		f.Close()
+
+		return "", err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return "", err
+	}
+
+	editor := editorCommand()
+
+	c := exec.Command(editor[0], append(editor[1:], f.Name())...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+
+	err = c.Run()
+	if err != nil {
+		return "", fmt.Errorf("editor '%s' failed: %w", strings.Join(editor, " "), err)
+	}
+
+	content, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+func formatEditHeader(title string, tags []string, body string) string {
+	return fmt.Sprintf("%s\ntitle: %s\ntags: %s\n%s\n%s",
+		editHeaderDelimiter, title, strings.Join(tags, ", "), editHeaderDelimiter, body)
+}
+
+// parseEditHeader splits the text into the header fields and the body. If
+// the text has no header, the fields are returned unchanged.
+func parseEditHeader(text string, title string, tags []string) (string, []string, string) {
+	if !strings.HasPrefix(text, editHeaderDelimiter+"\n") {
+		return title, tags, text
+	}
+
+	header, body, found := strings.Cut(strings.TrimPrefix(text, editHeaderDelimiter+"\n"), "\n"+editHeaderDelimiter+"\n")
+	if !found {
+		return title, tags, text
+	}
+
+	for _, line := range strings.Split(header, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+
+		switch strings.TrimSpace(strings.ToLower(key)) {
+		case "title":
+			title = value
+		case "tags":
+			tags = nil
+
+			for _, tag := range strings.Split(value, ",") {
+				if tag = strings.TrimSpace(tag); len(tag) != 0 {
+					tags = append(tags, tag)
+				}
+			}
+		}
+	}
+
+	return title, tags, body
+}
+
+// stdin is shared by all prompts, so that no buffered input gets lost
+// between them.
+var stdin = bufio.NewReader(os.Stdin)
+
+func prompt(question string) (string, error) {
+	answer, err := promptValue(question)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ToLower(answer), nil
+}
+
+// promptValue asks the question and returns the answer as entered.
+func promptValue(question string) (string, error) {
+	fmt.Print(question)
+
+	answer, err := stdin.ReadString('\n')
+	if err != nil && (err != io.EOF || len(answer) == 0) {
+		return "", err
+	}
+
+	return strings.TrimSpace(answer), nil
+}
+
+func equalTags(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (cmd *EditCmd) Run(ctx *Globals) error {
+	note, err := client.FindNote(cmd.Note)
+	if err != nil {
+		return err
+	}
+
+	noteTags, err := client.GetNoteTags(note.ID)
+	if err != nil {
+		return err
+	}
+
+	var tags []string
+
+	for _, tag := range noteTags {
+		tags = append(tags, tag.Title)
+	}
+
+	original := note.Body
+	if !cmd.NoHeader {
+		original = formatEditHeader(note.Title, tags, note.Body)
+	}
+
+	edited, err := editText(original)
+	if err != nil {
+		return err
+	}
+
+	if edited == original {
+		fmt.Println("No changes.")
+
+		return nil
+	}
+
+	current, err := client.GetNote(note.ID, "id,title,body,updated_time")
+	if err != nil {
+		return err
+	}
+
+	currentNoteTags, err := client.GetNoteTags(note.ID)
+	if err != nil {
+		return err
+	}
+
+	var currentTags []string
+
+	for _, tag := range currentNoteTags {
+		currentTags = append(currentTags, tag.Title)
+	}
+
+	if current.UpdatedTime != note.UpdatedTime || !equalTags(tags, currentTags) {
+		answer, err := prompt("The note has been changed in Joplin while editing. [m]erge, [o]verwrite or [a]bort? ")
+		if err != nil {
+			return err
+		}
+
+		switch answer {
+		case "m", "merge":
+			theirs := current.Body
+			if !cmd.NoHeader {
+				theirs = formatEditHeader(current.Title, currentTags, current.Body)
+			}
+
+			merged, conflicts := goplin.Merge3(original, edited, theirs)
+			if conflicts {
+				fmt.Println("The changes conflict, please resolve the conflicts in the editor.")
+			}
+
+			for conflicts {
+				merged, err = editText(merged)
+				if err != nil {
+					return err
+				}
+
+				if !goplin.HasConflictMarkers(merged) {
+					break
+				}
+
+				answer, err := prompt("The note still contains conflict markers. [e]dit again or [a]bort? ")
+				if err != nil {
+					return err
+				}
+
+				if answer != "e" && answer != "edit" {
+					return fmt.Errorf("aborted, your changes have not been saved")
+				}
+			}
+
+			edited = merged
+			tags = currentTags
+		case "o", "overwrite":
+			tags = currentTags
+		default:
+			return fmt.Errorf("aborted, your changes have not been saved")
+		}
+	}
+
+	title, newTags, body := note.Title, tags, edited
+
+	if !cmd.NoHeader {
+		title, newTags, body = parseEditHeader(edited, note.Title, tags)
+	}
+
+	props := map[string]interface{}{
+		"body": body,
+	}
+
+	if title != note.Title {
+		props["title"] = title
+	}
+
+	_, err = client.UpdateNote(note.ID, props)
+	if err != nil {
+		return err
+	}
+
+	if !equalTags(tags, newTags) {
+		err = client.SetNoteTags(note, newTags)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Note '%s' updated\n", title)
+
+	return nil
+}
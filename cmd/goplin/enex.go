@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/piccobit/goplin"
+)
+
+type ImportENEXCmd struct {
+	Into string `required help:"Name or ID of the notebook to import into."`
+
+	File string `arg name:"file" help:"Name of the Evernote export file to read."`
+}
+
+func (cmd *ImportENEXCmd) Run(ctx *Globals) error {
+	f, err := os.Open(cmd.File)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	notes, err := goplin.ReadENEX(f)
+	if err != nil {
+		return fmt.Errorf("could not read '%s': %w", cmd.File, err)
+	}
+
+	notebook, err := client.FindNotebook(cmd.Into)
+	if err != nil {
+		return err
+	}
+
+	created, err := client.ImportENEX(notes, notebook.ID)
+
+	fmt.Printf("Imported %d of %d notes from '%s'\n", len(created), len(notes), cmd.File)
+
+	return err
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/piccobit/goplin"
+)
+
+type ExportEPUBCmd struct {
+	Notebook    string `help:"Export the notes of the specified notebook (name or ID)."`
+	Tag         string `help:"Export the notes with the specified tag (name or ID)."`
+	Query       string `help:"Export the notes matching the specified search query."`
+	NoRecursive bool   `name:"no-recursive" help:"Do not include the notes of sub-notebooks."`
+	Title       string `help:"Title of the book. Defaults to the name of the notebook."`
+	Author      string `help:"Author of the book."`
+	Language    string `default:"en" help:"Language of the book."`
+	OrderBy     string `name:"order-by" help:"Order chapters by specified field. Defaults to the notebook order."`
+	OrderDir    string `name:"order-dir" default:"ASC" help:"Order chapters by specified direction: ASC or DESC."`
+
+	File string `arg name:"file" help:"Name of the EPUB file to write."`
+}
+
+func (cmd *ExportEPUBCmd) Run(ctx *Globals) error {
+	if len(cmd.Notebook) == 0 && len(cmd.Tag) == 0 {
+		return fmt.Errorf("either a notebook or a tag has to be specified")
+	}
+
+	f, err := os.Create(cmd.File)
+	if err != nil {
+		return err
+	}
+
+	err = client.ExportEPUB(f, goplin.Selection{
+		Notebook:  cmd.Notebook,
+		Tag:       cmd.Tag,
+		Query:     cmd.Query,
+		Recursive: !cmd.NoRecursive,
+	}, goplin.EPUBOptions{
+		Title:    cmd.Title,
+		Author:   cmd.Author,
+		Language: cmd.Language,
+		OrderBy:  cmd.OrderBy,
+		OrderDir: cmd.OrderDir,
+		Warnings: os.Stderr,
+	})
+	if err != nil {
+		f.Close()
+
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported EPUB to '%s'\n", cmd.File)
+
+	return nil
+}
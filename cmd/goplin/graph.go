@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/piccobit/goplin"
+)
+
+type GraphExportCmd struct {
+	Format      string `enum:"dot,graphml,json" default:"dot" help:"Output format: dot, graphml or json."`
+	Edges       string `default:"link" help:"Comma separated edge types: link, tag and/or notebook."`
+	Notebook    string `help:"Include only the notes of the specified notebook (name or ID)."`
+	Tag         string `help:"Include only the notes with the specified tag (name or ID)."`
+	Query       string `help:"Include only the notes matching the specified search query."`
+	NoRecursive bool   `name:"no-recursive" help:"Do not include the notes of sub-notebooks."`
+	Output      string `short:"o" help:"Write the graph to the specified file instead of stdout."`
+}
+
+func (cmd *GraphExportCmd) Run(ctx *Globals) error {
+	var edgeTypes []string
+
+	for _, edgeType := range strings.Split(cmd.Edges, ",") {
+		if edgeType = strings.TrimSpace(edgeType); len(edgeType) != 0 {
+			edgeTypes = append(edgeTypes, edgeType)
+		}
+	}
+
+	g, err := client.NoteGraph(goplin.Selection{
+		Notebook:  cmd.Notebook,
+		Tag:       cmd.Tag,
+		Query:     cmd.Query,
+		Recursive: !cmd.NoRecursive,
+	}, edgeTypes)
+	if err != nil {
+		return err
+	}
+
+	w := os.Stdout
+
+	if len(cmd.Output) != 0 {
+		w, err = os.Create(cmd.Output)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch cmd.Format {
+	case "graphml":
+		err = g.WriteGraphML(w)
+	case "json":
+		err = g.WriteJSON(w)
+	default:
+		err = g.WriteDOT(w)
+	}
+
+	if w != os.Stdout {
+		closeErr := w.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/piccobit/goplin"
+)
+
+type ExportJEXCmd struct {
+	Notebook    string `help:"Export the notes of the specified notebook (name or ID)."`
+	Tag         string `help:"Export the notes with the specified tag (name or ID)."`
+	Query       string `help:"Export the notes matching the specified search query."`
+	NoRecursive bool   `name:"no-recursive" help:"Do not include the notes of sub-notebooks."`
+
+	File string `arg name:"file" help:"Name of the JEX file to write."`
+}
+
+type ImportJEXCmd struct {
+	Into string `help:"Name or ID of the notebook to import into. Defaults to the top level."`
+
+	File string `arg name:"file" help:"Name of the JEX file to read."`
+}
+
+func (cmd *ExportJEXCmd) Run(ctx *Globals) error {
+	notes, err := client.SelectNotes(goplin.Selection{
+		Notebook:  cmd.Notebook,
+		Tag:       cmd.Tag,
+		Query:     cmd.Query,
+		Recursive: !cmd.NoRecursive,
+	}, goplin.AllNoteFields)
+	if err != nil {
+		return err
+	}
+
+	archive, err := client.CollectJEX(notes)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(cmd.File)
+	if err != nil {
+		return err
+	}
+
+	err = archive.Write(f)
+	if err != nil {
+		f.Close()
+
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d notes, %d notebooks, %d tags and %d resources to '%s'\n",
+		len(archive.Notes), len(archive.Notebooks), len(archive.Tags), len(archive.Resources), cmd.File)
+
+	return nil
+}
+
+func (cmd *ImportJEXCmd) Run(ctx *Globals) error {
+	f, err := os.Open(cmd.File)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	archive, err := goplin.ReadJEX(f)
+	if err != nil {
+		return err
+	}
+
+	notebookID := ""
+
+	if len(cmd.Into) != 0 {
+		notebook, err := client.FindNotebook(cmd.Into)
+		if err != nil {
+			return err
+		}
+
+		notebookID = notebook.ID
+	}
+
+	err = client.ImportJEX(archive, notebookID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d notes, %d notebooks, %d tags and %d resources from '%s'\n",
+		len(archive.Notes), len(archive.Notebooks), len(archive.Tags), len(archive.Resources), cmd.File)
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/piccobit/goplin"
+)
+
+type LinksCmd struct {
+	NoHeader bool `help:"Do not print header."`
+
+	Note string `arg name:"note" help:"ID or title of the note."`
+}
+
+type BacklinksCmd struct {
+	NoHeader bool `help:"Do not print header."`
+
+	Note string `arg name:"note" help:"ID or title of the note."`
+}
+
+type CheckLinksCmd struct {
+	NoHeader bool `help:"Do not print header."`
+}
+
+func newLinkTable(title string, noHeader bool, columns ...interface{}) table.Writer {
+	t := table.NewWriter()
+	t.SetStyle(table.StyleBold)
+	t.SetOutputMirror(os.Stdout)
+
+	if !noHeader {
+		t.SetTitle(title)
+		t.AppendHeader(columns)
+	}
+
+	return t
+}
+
+func (cmd *LinksCmd) Run(ctx *Globals) error {
+	note, err := client.FindNote(cmd.Note)
+	if err != nil {
+		return err
+	}
+
+	links := goplin.ParseLinks(note.ID, note.Body)
+
+	targets, err := client.LinkTargets(links)
+	if err != nil {
+		return err
+	}
+
+	t := newLinkTable("Links of '"+note.Title+"'", cmd.NoHeader, "line", "id", "title", "text")
+
+	for _, link := range links {
+		title, ok := targets[link.TargetID]
+		if !ok {
+			title = "<missing>"
+		}
+
+		t.AppendRow(table.Row{strconv.Itoa(link.Line), link.TargetID, title, link.Text})
+	}
+
+	t.Render()
+
+	return nil
+}
+
+func (cmd *BacklinksCmd) Run(ctx *Globals) error {
+	note, err := client.FindNote(cmd.Note)
+	if err != nil {
+		return err
+	}
+
+	g, err := client.LinkGraph()
+	if err != nil {
+		return err
+	}
+
+	t := newLinkTable("Backlinks of '"+note.Title+"'", cmd.NoHeader, "id", "title", "line", "text")
+
+	for _, link := range g.Backlinks(note.ID) {
+		t.AppendRow(table.Row{link.SourceID, g.Title(link.SourceID), strconv.Itoa(link.Line), link.Text})
+	}
+
+	t.Render()
+
+	return nil
+}
+
+func (cmd *CheckLinksCmd) Run(ctx *Globals) error {
+	g, err := client.LinkGraph()
+	if err != nil {
+		return err
+	}
+
+	broken := g.BrokenLinks()
+
+	if len(broken) == 0 {
+		fmt.Println("No broken links found.")
+
+		return nil
+	}
+
+	t := newLinkTable("Broken links", cmd.NoHeader, "id", "title", "line", "target", "text")
+
+	for _, link := range broken {
+		t.AppendRow(table.Row{link.SourceID, g.Title(link.SourceID), strconv.Itoa(link.Line), link.TargetID, link.Text})
+	}
+
+	t.Render()
+
+	return fmt.Errorf("found %d broken links", len(broken))
+}
@@ -1,22 +1,26 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"os"
-	"path"
 	"reflect"
 	"strings"
 
 	"github.com/alecthomas/kong"
-	"github.com/imroc/req/v3"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/piccobit/goplin"
-	"github.com/spf13/viper"
 )
 
 type Globals struct {
-	Debug bool `help:"Enable debug output."`
+	Debug          bool `env:"GOPLIN_DEBUG" help:"Enable debug output. The API token is masked."`
+	DebugBodyLimit int  `name:"debug-body-limit" env:"GOPLIN_DEBUG_BODY_LIMIT" help:"Truncate lines of the debug output longer than the limit."`
+
+	Config  string `type:"path" env:"GOPLIN_CONFIG" help:"Path of the config file. Defaults to $HOME/.goplin."`
+	Profile string `env:"GOPLIN_PROFILE" help:"Name of the profile in the config file to use."`
+	Token   string `env:"GOPLIN_TOKEN" help:"API token of the Joplin Web Clipper service."`
+	Host    string `env:"GOPLIN_HOST" help:"Host of the Joplin Web Clipper service. Defaults to localhost."`
+	Port    int    `env:"GOPLIN_PORT" help:"Port of the Joplin Web Clipper service. Searched for if not set."`
 }
 
 type ListTagsCmd struct {
@@ -74,11 +78,16 @@ type SearchCmd struct {
 }
 
 type CreateNoteCmd struct {
-	Format string `help:"Format of the new note: Markdown or HTML"`
+	Format   string            `help:"Format of the new note: Markdown or HTML"`
+	Template string            `help:"Create the note from the named template. The arguments are then [title] [notebook] [tags...]."`
+	Vars     map[string]string `name:"var" placeholder:"NAME=VALUE" help:"Set a template variable."`
+	Output   string            `short:"o" enum:"text,id,link,json" default:"text" help:"Print the new note as text, id, link or json."`
 
-	Title    string   `arg name:"title" help:"Title of the new note."`
-	Body     string   `arg name:"body" help:"Body of the new note. Prefixing the string with a '@' will read the body from the given file."`
-	Notebook string   `arg name:"notebook" help:"Name of the notebook to store the note in."`
+	CreateMissing bool `name:"create-missing" help:"Create missing tags and notebooks instead of failing."`
+
+	Title    string   `arg optional name:"title" help:"Title of the new note."`
+	Body     string   `arg optional name:"body" help:"Body of the new note. Prefixing the string with a '@' will read the body from the given file, '-' reads it from stdin."`
+	Notebook string   `arg optional name:"notebook" help:"Name, ID or path of the notebook to store the note in."`
 	Tags     []string `arg optional name:"tags" help:"Tags to attach to the new note."`
 }
 
@@ -96,6 +105,15 @@ type ListResourcesCmd struct {
 type CLI struct {
 	Globals
 
+	Auth struct {
+		Login    AuthLoginCmd    `cmd help:"Request an API token from Joplin."`
+		Status   AuthStatusCmd   `cmd help:"Check the stored API token."`
+		Logout   AuthLogoutCmd   `cmd help:"Remove the stored API token."`
+		SetToken AuthSetTokenCmd `cmd name:"set-token" help:"Store an API token copied from the Web Clipper options of Joplin."`
+	} `cmd help:"Joplin authorisation commands."`
+
+	Doctor DoctorCmd `cmd help:"Diagnose the connection to Joplin."`
+
 	List struct {
 		Tags      ListTagsCmd      `cmd requires help:"List tags."`
 		Notes     ListNotesCmd     `cmd requires help:"List notes."`
@@ -113,6 +131,42 @@ type CLI struct {
 	Create struct {
 		Note CreateNoteCmd `cmd requires help:"Create note."`
 	} `cmd help:"Joplin create commands."`
+
+	Capture CaptureCmd `cmd help:"Create a note from stdin or the given text."`
+
+	Edit    EditCmd    `cmd help:"Edit a note in $EDITOR."`
+	Append  AppendCmd  `cmd help:"Append text to a note."`
+	Prepend PrependCmd `cmd help:"Prepend text to a note."`
+
+	Links     LinksCmd     `cmd help:"List the links of a note to other notes."`
+	Backlinks BacklinksCmd `cmd help:"List the notes linking to a note."`
+
+	Stats StatsCmd `cmd help:"Show statistics about notes, notebooks, tags and resources."`
+
+	Graph struct {
+		Export GraphExportCmd `cmd help:"Export the graph of notes to DOT, GraphML or JSON."`
+	} `cmd help:"Joplin graph commands."`
+
+	Check struct {
+		Links CheckLinksCmd `cmd help:"Report links to notes and resources which do not exist anymore."`
+	} `cmd help:"Joplin check commands."`
+
+	Daily   DailyCmd   `cmd help:"Open or create the note of the day."`
+	Weekly  WeeklyCmd  `cmd help:"Open or create the note of the week."`
+	Monthly MonthlyCmd `cmd help:"Open or create the note of the month."`
+
+	Export struct {
+		JEX      ExportJEXCmd      `cmd name:"jex" help:"Export notes to a JEX archive."`
+		EPUB     ExportEPUBCmd     `cmd name:"epub" help:"Export a notebook or tag as an EPUB book."`
+		Obsidian ExportObsidianCmd `cmd help:"Export notes to an Obsidian vault."`
+		Site     ExportSiteCmd     `cmd help:"Export a notebook as a static HTML site."`
+	} `cmd help:"Joplin export commands."`
+
+	Import struct {
+		JEX      ImportJEXCmd      `cmd name:"jex" help:"Import notes from a JEX archive."`
+		ENEX     ImportENEXCmd     `cmd name:"enex" help:"Import notes from an Evernote export file."`
+		Obsidian ImportObsidianCmd `cmd help:"Import notes from an Obsidian vault."`
+	} `cmd help:"Joplin import commands."`
 }
 
 var (
@@ -120,17 +174,12 @@ var (
 )
 
 func (cmd *ListTagsCmd) Run(ctx *Globals) error {
-	if ctx.Debug {
-		req.EnableDumpAll()
-		req.EnableDebugLog()
-	}
-
 	t := table.NewWriter()
 	t.SetStyle(table.StyleBold)
 	t.SetOutputMirror(os.Stdout)
 
 	if len(cmd.Fields) == 0 {
-		cmd.Fields = "id,parent_id,title"
+		cmd.Fields = configString("fields.tags", "id,parent_id,title")
 	}
 
 	if !cmd.NoHeader {
@@ -176,15 +225,21 @@ func (cmd *ListTagsCmd) Run(ctx *Globals) error {
 		} else {
 			orphansFound := 0
 
-			for _, tag := range tags {
+			var tagNotes [][]goplin.Note
+			var bulkErr *goplin.BulkError
+
+			if cmd.OrphansOnly {
+				tagNotes, err = client.GetNotesByTags(goplin.TagIDs(tags), "", "")
+				errors.As(err, &bulkErr)
+			}
+
+			for i, tag := range tags {
 				if cmd.OrphansOnly {
-					var notes []goplin.Note
-					notes, err = client.GetNotesByTag(tag.ID, cmd.OrderBy, cmd.OrderDir)
-					if err != nil {
+					if bulkErr.Err(tag.ID) != nil {
 						continue
 					}
 
-					if len(notes) == 0 {
+					if len(tagNotes[i]) == 0 {
 						orphansFound++
 						PrintTableRow(t, tag, cmd.Fields, &goplin.TagFormats)
 					}
@@ -200,12 +255,16 @@ func (cmd *ListTagsCmd) Run(ctx *Globals) error {
 			}
 		}
 	} else {
-		for _, id := range cmd.IDs {
-			tag, err := client.GetTag(id, cmd.Fields)
-			if err != nil {
+		var bulkErr *goplin.BulkError
+
+		tags, err := client.GetTags(cmd.IDs, cmd.Fields)
+		errors.As(err, &bulkErr)
+
+		for i, id := range cmd.IDs {
+			if bulkErr.Err(id) != nil {
 				fmt.Printf("%-32s <= ERROR: tag not found\n", id)
 			} else {
-				PrintTableRow(t, tag, cmd.Fields, &goplin.TagFormats)
+				PrintTableRow(t, tags[i], cmd.Fields, &goplin.TagFormats)
 			}
 		}
 	}
@@ -219,17 +278,12 @@ func (cmd *ListNotesCmd) Run(ctx *Globals) error {
 	var err error
 	var notes []goplin.Note
 
-	if ctx.Debug {
-		req.EnableDumpAll()
-		req.EnableDebugLog()
-	}
-
 	t := table.NewWriter()
 	t.SetStyle(table.StyleBold)
 	t.SetOutputMirror(os.Stdout)
 
 	if len(cmd.Fields) == 0 {
-		cmd.Fields = "id,parent_id,title"
+		cmd.Fields = configString("fields.notes", "id,parent_id,title")
 	}
 
 	if !cmd.NoHeader {
@@ -251,26 +305,31 @@ func (cmd *ListNotesCmd) Run(ctx *Globals) error {
 			PrintTableRow(t, note, cmd.Fields, &goplin.NoteFormats)
 		}
 	} else {
+		var bulkErr *goplin.BulkError
+
 		if strings.ToLower(cmd.By) == "tag" {
-			for _, id := range cmd.IDs {
-				notes, err := client.GetNotesByTag(id, cmd.OrderBy, cmd.OrderDir)
-				if err != nil {
+			tagNotes, err := client.GetNotesByTags(cmd.IDs, cmd.OrderBy, cmd.OrderDir)
+			errors.As(err, &bulkErr)
+
+			for i, id := range cmd.IDs {
+				if bulkErr.Err(id) != nil {
 					fmt.Printf("%-32s <= ERROR: note not found\n", id)
 				} else {
-					for _, note := range notes {
+					for _, note := range tagNotes[i] {
 						PrintTableRow(t, note, cmd.Fields, &goplin.NoteFormats)
 					}
 				}
 			}
 		} else {
-			for _, id := range cmd.IDs {
-				note, err := client.GetNote(id, cmd.Fields)
-				if err != nil {
+			notes, err = client.GetNotes(cmd.IDs, cmd.Fields)
+			errors.As(err, &bulkErr)
+
+			for i, id := range cmd.IDs {
+				if bulkErr.Err(id) != nil {
 					fmt.Printf("%-32s <= ERROR: note not found\n", id)
 				} else {
-					PrintTableRow(t, note, cmd.Fields, &goplin.NoteFormats)
+					PrintTableRow(t, notes[i], cmd.Fields, &goplin.NoteFormats)
 				}
-
 			}
 		}
 	}
@@ -281,17 +340,12 @@ func (cmd *ListNotesCmd) Run(ctx *Globals) error {
 }
 
 func (cmd *ListNotebooksCmd) Run(ctx *Globals) error {
-	if ctx.Debug {
-		req.EnableDumpAll()
-		req.EnableDebugLog()
-	}
-
 	t := table.NewWriter()
 	t.SetStyle(table.StyleBold)
 	t.SetOutputMirror(os.Stdout)
 
 	if len(cmd.Fields) == 0 {
-		cmd.Fields = "id,parent_id,title"
+		cmd.Fields = configString("fields.notebooks", "id,parent_id,title")
 	}
 
 	if !cmd.NoHeader {
@@ -308,14 +362,17 @@ func (cmd *ListNotebooksCmd) Run(ctx *Globals) error {
 			PrintTableRow(t, notebook, cmd.Fields, &goplin.NoteFormats)
 		}
 	} else {
-		for _, id := range cmd.IDs {
-			note, err := client.GetNotebook(id, cmd.Fields)
-			if err != nil {
+		var bulkErr *goplin.BulkError
+
+		notebooks, err := client.GetNotebooks(cmd.IDs, cmd.Fields)
+		errors.As(err, &bulkErr)
+
+		for i, id := range cmd.IDs {
+			if bulkErr.Err(id) != nil {
 				fmt.Printf("%-32s <= ERROR: notebook not found\n", id)
 			} else {
-				PrintTableRow(t, note, cmd.Fields, &goplin.NoteFormats)
+				PrintTableRow(t, notebooks[i], cmd.Fields, &goplin.NoteFormats)
 			}
-
 		}
 	}
 
@@ -325,14 +382,13 @@ func (cmd *ListNotebooksCmd) Run(ctx *Globals) error {
 }
 
 func (cmd *DeleteTagsCmd) Run(ctx *Globals) error {
-	if ctx.Debug {
-		req.EnableDumpAll()
-		req.EnableDebugLog()
-	}
+	var bulkErr *goplin.BulkError
+
+	err := client.DeleteTags(cmd.IDs)
+	errors.As(err, &bulkErr)
 
 	for _, id := range cmd.IDs {
-		err := client.DeleteTag(id)
-		if err != nil {
+		if bulkErr.Err(id) != nil {
 			fmt.Printf("Could not find tag with ID '%s'\n", id)
 		} else {
 			fmt.Printf("Tag with ID '%s' deleted'\n", id)
@@ -343,11 +399,6 @@ func (cmd *DeleteTagsCmd) Run(ctx *Globals) error {
 }
 
 func (cmd *DeleteTagFromNoteCmd) Run(ctx *Globals) error {
-	if ctx.Debug {
-		req.EnableDumpAll()
-		req.EnableDebugLog()
-	}
-
 	err := client.DeleteTagFromNote(cmd.TagID.TagID, cmd.TagID.From.NoteID.NoteID)
 	if err != nil {
 		fmt.Printf("Could not find tag with ID '%s'\n", cmd.TagID)
@@ -359,17 +410,12 @@ func (cmd *DeleteTagFromNoteCmd) Run(ctx *Globals) error {
 }
 
 func (cmd *SearchCmd) Run(ctx *Globals) error {
-	if ctx.Debug {
-		req.EnableDumpAll()
-		req.EnableDebugLog()
-	}
-
 	t := table.NewWriter()
 	t.SetStyle(table.StyleBold)
 	t.SetOutputMirror(os.Stdout)
 
 	if len(cmd.Fields) == 0 {
-		cmd.Fields = "id,parent_id,title"
+		cmd.Fields = configString("fields.search", "id,parent_id,title")
 	}
 
 	if !cmd.NoHeader {
@@ -391,9 +437,12 @@ func (cmd *SearchCmd) Run(ctx *Globals) error {
 }
 
 func (cmd *CreateNoteCmd) Run(ctx *Globals) error {
-	if ctx.Debug {
-		req.EnableDumpAll()
-		req.EnableDebugLog()
+	if len(cmd.Template) != 0 {
+		return cmd.createFromTemplate()
+	}
+
+	if len(cmd.Title) == 0 || len(cmd.Body) == 0 || len(cmd.Notebook) == 0 {
+		return fmt.Errorf("expected <title> <body> <notebook>")
 	}
 
 	format := goplin.Undefined
@@ -407,7 +456,12 @@ func (cmd *CreateNoteCmd) Run(ctx *Globals) error {
 		format = goplin.HTML
 	}
 
-	return client.CreateNote(cmd.Title, format, cmd.Body, cmd.Notebook, cmd.Tags)
+	note, err := client.CreateNote(cmd.Title, format, cmd.Body, cmd.Notebook, cmd.Tags, cmd.CreateMissing)
+	if err != nil {
+		return err
+	}
+
+	return printCreatedNote(note, cmd.Output, "Created note")
 }
 
 func PrintTableHeader(t table.Writer, title string, fields string) {
@@ -454,67 +508,38 @@ func PrintTableRow(t table.Writer, cell interface{}, fields string, format *map[
 }
 
 func main() {
-	var err error
-
-	viper.SetDefault("api_token", "")
-	viper.SetConfigName(".goplin") // name of config file (without extension)
-	viper.SetConfigType("yaml")    // REQUIRED if the config file does not have the extension in the name
-	viper.AddConfigPath("$HOME")   // call multiple times to add many search paths
-	err = viper.ReadInConfig()     // Find and read the config file
-	if err != nil {                // handle errors reading the config file
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			// Config file not found; ignore error if desired
-		} else {
-			// Config file was found but another error was produced
-			panic(fmt.Errorf("fatal error config file: %w", err))
-		}
+	cli := CLI{
+		Globals: Globals{},
 	}
 
-	apiToken := viper.GetString("api_token")
-
-	client, err = goplin.New(apiToken)
-	if err != nil {
-		log.Fatal(err)
-	}
+	ctx := kong.Parse(&cli)
 
-	if len(apiToken) == 0 {
-		viper.Set("api_token", client.GetApiToken())
+	_, createProfile := ctx.Selected().Target.Addr().Interface().(profileCreator)
 
-		userHomeDir, err := os.UserHomeDir()
-		if err != nil {
-			log.Fatal(err)
-		}
+	err := loadConfig(&cli.Globals, createProfile)
+	ctx.FatalIfErrorf(err)
 
-		configFilePath := path.Join(userHomeDir, ".goplin")
+	// The client is only created for commands which talk to Joplin.
+	if _, ok := ctx.Selected().Target.Addr().Interface().(offlineCommand); !ok {
+		err = connect(&cli.Globals)
+		ctx.FatalIfErrorf(err)
+	}
 
-		err = viper.WriteConfigAs(configFilePath)
-		if err != nil {
-			log.Fatal(err)
-		}
+	err = ctx.Run(&cli.Globals)
 
-		err = os.Chmod(configFilePath, 0600)
-		if err != nil {
-			log.Fatal(err)
+	if client != nil {
+		closeErr := client.Close()
+		if err == nil {
+			err = closeErr
 		}
 	}
 
-	cli := CLI{
-		Globals: Globals{},
-	}
-
-	ctx := kong.Parse(&cli)
-	err = ctx.Run(&cli.Globals)
 	ctx.FatalIfErrorf(err)
 }
 
 func (cmd *ListResourcesCmd) Run(ctx *Globals) error {
-	if ctx.Debug {
-		req.EnableDumpAll()
-		req.EnableDebugLog()
-	}
-
 	if len(cmd.Fields) == 0 {
-		cmd.Fields = "id,title"
+		cmd.Fields = configString("fields.resources", "id,title")
 	}
 
 	t := table.NewWriter()
@@ -526,7 +551,7 @@ func (cmd *ListResourcesCmd) Run(ctx *Globals) error {
 	}
 
 	if len(cmd.IDs) == 0 {
-		resources, err := client.GetAllResources(cmd.OrderBy, cmd.OrderDir)
+		resources, err := client.GetAllResources(cmd.Fields, cmd.OrderBy, cmd.OrderDir)
 		if err != nil {
 			return err
 		}
@@ -535,12 +560,16 @@ func (cmd *ListResourcesCmd) Run(ctx *Globals) error {
 			PrintTableRow(t, resource, cmd.Fields, &goplin.ResourceFormats)
 		}
 	} else {
-		for _, id := range cmd.IDs {
-			resource, err := client.GetResource(id, cmd.Fields)
-			if err != nil {
-				fmt.Printf("%-32s <= ERROR: tag not found\n", id)
+		var bulkErr *goplin.BulkError
+
+		resources, err := client.GetResources(cmd.IDs, cmd.Fields)
+		errors.As(err, &bulkErr)
+
+		for i, id := range cmd.IDs {
+			if bulkErr.Err(id) != nil {
+				fmt.Printf("%-32s <= ERROR: resource not found\n", id)
 			} else {
-				PrintTableRow(t, resource, cmd.Fields, &goplin.ResourceFormats)
+				PrintTableRow(t, resources[i], cmd.Fields, &goplin.ResourceFormats)
 			}
 		}
 	}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/piccobit/goplin"
+)
+
+type ExportObsidianCmd struct {
+	Notebook    string `help:"Export the notes of the specified notebook (name or ID)."`
+	Tag         string `help:"Export the notes with the specified tag (name or ID)."`
+	Query       string `help:"Export the notes matching the specified search query."`
+	NoRecursive bool   `name:"no-recursive" help:"Do not include the notes of sub-notebooks."`
+
+	Dir string `arg name:"dir" type:"path" help:"Directory of the Obsidian vault to write."`
+}
+
+type ImportObsidianCmd struct {
+	Into string `help:"Name or ID of the notebook to import into. Defaults to a new notebook named after the vault."`
+
+	Dir string `arg name:"dir" type:"existingdir" help:"Directory of the Obsidian vault to read."`
+}
+
+func (cmd *ExportObsidianCmd) Run(ctx *Globals) error {
+	rootID := ""
+
+	if len(cmd.Notebook) != 0 {
+		notebook, err := client.FindNotebook(cmd.Notebook)
+		if err != nil {
+			return err
+		}
+
+		rootID = notebook.ID
+	}
+
+	notes, err := client.SelectNotes(goplin.Selection{
+		Notebook:  rootID,
+		Tag:       cmd.Tag,
+		Query:     cmd.Query,
+		Recursive: !cmd.NoRecursive,
+	}, goplin.AllNoteFields)
+	if err != nil {
+		return err
+	}
+
+	err = client.ExportObsidianVault(cmd.Dir, notes, rootID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d notes to '%s'\n", len(notes), cmd.Dir)
+
+	return nil
+}
+
+func (cmd *ImportObsidianCmd) Run(ctx *Globals) error {
+	notebookID := ""
+
+	if len(cmd.Into) != 0 {
+		notebook, err := client.FindNotebook(cmd.Into)
+		if err != nil {
+			return err
+		}
+
+		notebookID = notebook.ID
+	}
+
+	notes, err := client.ImportObsidianVault(cmd.Dir, notebookID)
+
+	fmt.Printf("Imported %d notes from '%s'\n", len(notes), cmd.Dir)
+
+	return err
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/piccobit/goplin"
+)
+
+// printCreatedNote prints the new note in the requested output format: a
+// message for 'text', the ID for 'id', the Markdown link for 'link' or the
+// note as 'json'.
+func printCreatedNote(note goplin.Note, output string, message string) error {
+	switch output {
+	case "id":
+		fmt.Println(note.ID)
+	case "link":
+		fmt.Printf("[%s](:/%s)\n", note.Title, note.ID)
+	case "json":
+		data, err := json.MarshalIndent(note, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(data))
+	default:
+		fmt.Printf("%s '%s' (%s)\n", message, note.Title, note.ID)
+	}
+
+	return nil
+}
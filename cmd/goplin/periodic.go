@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/piccobit/goplin"
+)
+
+type DailyCmd struct {
+	Date string `help:"Date of the note (YYYY-MM-DD). Defaults to today."`
+	Edit bool   `help:"Open the note in $EDITOR."`
+}
+
+type WeeklyCmd struct {
+	Date string `help:"Any date of the week of the note (YYYY-MM-DD). Defaults to today."`
+	Edit bool   `help:"Open the note in $EDITOR."`
+}
+
+type MonthlyCmd struct {
+	Date string `help:"Any date of the month of the note (YYYY-MM-DD). Defaults to today."`
+	Edit bool   `help:"Open the note in $EDITOR."`
+}
+
+// periodicNote opens or creates the note for the period. The notebook path,
+// title and template are read from the config section named after the
+// period, e.g. 'daily.notebook'.
+func periodicNote(ctx *Globals, period goplin.Period, date string, edit bool) error {
+	opts := goplin.PeriodicNoteOptions{
+		Period:       period,
+		NotebookPath: configString(period.String()+".notebook", ""),
+		Title:        configString(period.String()+".title", ""),
+		Template:     configString(period.String()+".template", ""),
+	}
+
+	if len(date) != 0 {
+		t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid date '%s'", date)
+		}
+
+		opts.Date = t
+	}
+
+	note, created, err := client.PeriodicNote(opts)
+	if err != nil {
+		return err
+	}
+
+	if edit {
+		editCmd := EditCmd{Note: note.ID}
+
+		return editCmd.Run(ctx)
+	}
+
+	if created {
+		fmt.Printf("Created note '%s' (%s)\n", note.Title, note.ID)
+	} else {
+		fmt.Printf("Found note '%s' (%s)\n", note.Title, note.ID)
+	}
+
+	return nil
+}
+
+func (cmd *DailyCmd) Run(ctx *Globals) error {
+	return periodicNote(ctx, goplin.Daily, cmd.Date, cmd.Edit)
+}
+
+func (cmd *WeeklyCmd) Run(ctx *Globals) error {
+	return periodicNote(ctx, goplin.Weekly, cmd.Date, cmd.Edit)
+}
+
+func (cmd *MonthlyCmd) Run(ctx *Globals) error {
+	return periodicNote(ctx, goplin.Monthly, cmd.Date, cmd.Edit)
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+)
+
+type ExportSiteCmd struct {
+	Notebook string `arg name:"notebook" help:"Name or ID of the notebook to export."`
+	Dir      string `arg name:"dir" type:"path" help:"Directory to write the site to."`
+}
+
+func (cmd *ExportSiteCmd) Run(ctx *Globals) error {
+	notebook, err := client.FindNotebook(cmd.Notebook)
+	if err != nil {
+		return err
+	}
+
+	err = client.ExportSite(cmd.Dir, notebook.ID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported notebook '%s' to '%s'\n", notebook.Title, cmd.Dir)
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/piccobit/goplin"
+)
+
+type StatsCmd struct {
+	Format string `enum:"table,json" default:"table" help:"Output format: table or json."`
+	Top    int    `default:"10" help:"Number of notes in the lists of the largest and the stalest notes."`
+}
+
+func newStatsTable(title string, columns ...interface{}) table.Writer {
+	t := table.NewWriter()
+	t.SetStyle(table.StyleBold)
+	t.SetOutputMirror(os.Stdout)
+	t.SetTitle(title)
+	t.AppendHeader(columns)
+
+	return t
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func renderNoteStats(title string, notes []goplin.NoteStats) {
+	t := newStatsTable(title, "id", "title", "notebook", "words", "characters", "updated")
+
+	for _, note := range notes {
+		t.AppendRow(table.Row{note.ID, note.Title, note.Notebook, note.Words, note.Characters, note.UpdatedTime.Format("2006-01-02")})
+	}
+
+	t.Render()
+}
+
+func (cmd *StatsCmd) Validate() error {
+	if cmd.Top < 0 {
+		return fmt.Errorf("--top must not be negative")
+	}
+
+	return nil
+}
+
+func (cmd *StatsCmd) Run(ctx *Globals) error {
+	s, err := client.Stats(cmd.Top)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(s)
+	}
+
+	t := newStatsTable("Totals", "", "count")
+	t.AppendRows([]table.Row{
+		{"notes", s.Notes},
+		{"notebooks", s.Notebooks},
+		{"tags", s.Tags},
+		{"resources", s.Resources},
+		{"words", s.Words},
+		{"characters", s.Characters},
+		{"resource size", formatSize(s.ResourceSize)},
+	})
+	t.Render()
+
+	t = newStatsTable("Resources per notebook", "notebook", "resources", "size")
+	for _, size := range s.ResourceSizes {
+		t.AppendRow(table.Row{size.Notebook, size.Resources, formatSize(size.Size)})
+	}
+	t.Render()
+
+	t = newStatsTable("Notes per tag", "tag", "notes")
+	for _, count := range s.NotesPerTag {
+		t.AppendRow(table.Row{count.Tag, count.Notes})
+	}
+	t.Render()
+
+	t = newStatsTable("Notes created per month", "month", "notes")
+	for _, count := range s.CreatedPerMonth {
+		t.AppendRow(table.Row{count.Month, count.Notes})
+	}
+	t.Render()
+
+	renderNoteStats("Largest notes", s.LargestNotes)
+	renderNoteStats("Stalest notes", s.StalestNotes)
+
+	return nil
+}
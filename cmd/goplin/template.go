@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/piccobit/goplin"
+)
+
+const defaultTemplatesDir = "~/.goplin-templates"
+
+func templatesDir() string {
+	return configString("templates_dir", defaultTemplatesDir)
+}
+
+// promptVars asks for the declared template variables which have not been
+// passed with '--var'.
+func promptVars(t *goplin.NoteTemplate, vars map[string]string) (map[string]string, error) {
+	values := make(map[string]string, len(vars))
+
+	for name, value := range vars {
+		values[name] = value
+	}
+
+	for _, v := range t.MissingVars(vars) {
+		question := v.Prompt
+		if len(question) == 0 {
+			question = v.Name
+		}
+
+		if len(v.Default) != 0 {
+			question += fmt.Sprintf(" [%s]", v.Default)
+		}
+
+		answer, err := promptValue(question + ": ")
+		if err != nil {
+			return nil, err
+		}
+
+		if len(answer) == 0 {
+			answer = v.Default
+		}
+
+		values[v.Name] = answer
+	}
+
+	return values, nil
+}
+
+func (cmd *CreateNoteCmd) createFromTemplate() error {
+	t, err := goplin.ReadNoteTemplate(templatesDir(), cmd.Template)
+	if err != nil {
+		return err
+	}
+
+	// Without a body the positional arguments are [title] [notebook] [tags...].
+	// Empty arguments keep their position and fall back to the template, e.g.
+	// an empty title uses the title of the template.
+	title, notebookName, tags := cmd.Title, cmd.Body, t.Tags
+
+	if len(notebookName) == 0 {
+		notebookName = t.Notebook
+	}
+
+	var argTags []string
+
+	for _, tag := range append([]string{cmd.Notebook}, cmd.Tags...) {
+		if len(tag) != 0 {
+			argTags = append(argTags, tag)
+		}
+	}
+
+	if len(argTags) != 0 {
+		tags = argTags
+	}
+
+	if len(notebookName) == 0 {
+		return fmt.Errorf("no notebook given and the template '%s' does not define one", cmd.Template)
+	}
+
+	vars, err := promptVars(t, cmd.Vars)
+	if err != nil {
+		return err
+	}
+
+	title, body, err := client.RenderNoteTemplate(t, title, vars)
+	if err != nil {
+		return fmt.Errorf("could not render template '%s': %w", cmd.Template, err)
+	}
+
+	note := goplin.Note{
+		Title: title,
+	}
+
+	if strings.ToLower(cmd.Format) == "html" {
+		note.BodyHTML = body
+	} else {
+		note.Body = body
+	}
+
+	note, err = client.CreateNoteIn(note, notebookName, tags, cmd.CreateMissing)
+	if err != nil {
+		return err
+	}
+
+	return printCreatedNote(note, cmd.Output, "Created note")
+}
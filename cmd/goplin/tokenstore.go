@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Token stores, selected with 'token_store' in the config file.
+const (
+	tokenStorePlain     = "plain"
+	tokenStoreEncrypted = "encrypted"
+	tokenStoreHelper    = "helper"
+)
+
+const encryptedTokenPrefix = "scrypt-aes-gcm:"
+
+func tokenStore() string {
+	return configString("token_store", tokenStorePlain)
+}
+
+// readPassphrase returns $GOPLIN_PASSPHRASE or asks for the passphrase on
+// the terminal without echoing it. stdin is left alone, it may carry the
+// input of the command.
+func readPassphrase(question string) (string, error) {
+	if passphrase := os.Getenv("GOPLIN_PASSPHRASE"); len(passphrase) != 0 {
+		return passphrase, nil
+	}
+
+	passphrase, err := readSecret(question)
+	if errors.Is(err, errNoTerminal) {
+		return "", fmt.Errorf("no terminal to ask for the passphrase, please set GOPLIN_PASSPHRASE")
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(passphrase) == 0 {
+		return "", fmt.Errorf("no passphrase given")
+	}
+
+	return passphrase, nil
+}
+
+func tokenKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+}
+
+// encryptToken encrypts the token with AES-GCM and a key derived from the
+// passphrase with scrypt. Salt and nonce are stored with the ciphertext.
+func encryptToken(token string, passphrase string) (string, error) {
+	salt := make([]byte, 16)
+
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+
+	key, err := tokenKey(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(append(salt, nonce...), nonce, []byte(token), nil)
+
+	return encryptedTokenPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func decryptToken(encrypted string, passphrase string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(encrypted, encryptedTokenPrefix))
+	if err != nil || !strings.HasPrefix(encrypted, encryptedTokenPrefix) {
+		return "", fmt.Errorf("the encrypted token is corrupt")
+	}
+
+	if len(data) < 16 {
+		return "", fmt.Errorf("the encrypted token is corrupt")
+	}
+
+	key, err := tokenKey(passphrase, data[:16])
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) < 16+gcm.NonceSize() {
+		return "", fmt.Errorf("the encrypted token is corrupt")
+	}
+
+	nonce := data[16 : 16+gcm.NonceSize()]
+
+	token, err := gcm.Open(nil, nonce, data[16+gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt the token, wrong passphrase?")
+	}
+
+	return string(token), nil
+}
+
+// runCredentialHelper runs the configured credential helper like git does:
+// the action ('get', 'store' or 'erase') is appended to the command and the
+// attributes are passed as 'key=value' lines on stdin. For 'get' the helper
+// prints the token as 'token=...'.
+func runCredentialHelper(action string, attributes map[string]string) (map[string]string, error) {
+	helper := configString("credential_helper", "")
+	if len(helper) == 0 {
+		return nil, fmt.Errorf("the token store is 'helper', but no 'credential_helper' is configured")
+	}
+
+	var input bytes.Buffer
+
+	for key, value := range attributes {
+		fmt.Fprintf(&input, "%s=%s\n", key, value)
+	}
+
+	input.WriteString("\n")
+
+	c := exec.Command("sh", "-c", helper+" "+action)
+	c.Stdin = &input
+	c.Stderr = os.Stderr
+
+	output, err := c.Output()
+	if err != nil {
+		return nil, fmt.Errorf("credential helper '%s' failed: %w", helper, err)
+	}
+
+	result := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		if key, value, found := strings.Cut(scanner.Text(), "="); found {
+			result[key] = value
+		}
+	}
+
+	return result, nil
+}
+
+func helperAttributes() map[string]string {
+	return map[string]string{
+		"protocol": "goplin",
+		"profile":  profileName(),
+	}
+}
+
+// loadToken returns the token of the active profile from the token store.
+func loadToken() (string, error) {
+	switch tokenStore() {
+	case tokenStoreEncrypted:
+		encrypted := viper.GetString(profileKey("api_token_encrypted"))
+		if len(encrypted) == 0 {
+			return "", nil
+		}
+
+		passphrase, err := readPassphrase("Passphrase of the API token: ")
+		if err != nil {
+			return "", err
+		}
+
+		return decryptToken(encrypted, passphrase)
+	case tokenStoreHelper:
+		result, err := runCredentialHelper("get", helperAttributes())
+		if err != nil {
+			return "", err
+		}
+
+		return result["token"], nil
+	case tokenStorePlain:
+		return viper.GetString(profileKey("api_token")), nil
+	}
+
+	return "", fmt.Errorf("unknown token store '%s'", tokenStore())
+}
+
+// saveToken stores the token of the active profile in the token store. An
+// empty token removes it.
+func saveToken(ctx *Globals, token string) error {
+	switch tokenStore() {
+	case tokenStoreEncrypted:
+		encrypted := ""
+
+		if len(token) != 0 {
+			passphrase, err := readPassphrase("Passphrase to encrypt the API token: ")
+			if err != nil {
+				return err
+			}
+
+			encrypted, err = encryptToken(token, passphrase)
+			if err != nil {
+				return err
+			}
+		}
+
+		viper.Set(profileKey("api_token_encrypted"), encrypted)
+		viper.Set(profileKey("api_token"), "")
+	case tokenStoreHelper:
+		attributes := helperAttributes()
+		action := "erase"
+
+		if len(token) != 0 {
+			attributes["token"] = token
+			action = "store"
+		}
+
+		_, err := runCredentialHelper(action, attributes)
+		if err != nil {
+			return err
+		}
+
+		viper.Set(profileKey("api_token"), "")
+	case tokenStorePlain:
+		viper.Set(profileKey("api_token"), token)
+	default:
+		return fmt.Errorf("unknown token store '%s'", tokenStore())
+	}
+
+	return saveConfig(ctx)
+}
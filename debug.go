@@ -0,0 +1,158 @@
+package goplin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/imroc/req/v3"
+)
+
+const redacted = "REDACTED"
+
+var (
+	tokenParamRegexp = regexp.MustCompile(`((?:^|[?&\s"])(?:auth_)?token=)[^&\s"]+`)
+	tokenJSONRegexp  = regexp.MustCompile(`("(?:auth_)?token"\s*:\s*")(?:[^"\\]|\\.)+"`)
+)
+
+// Redact masks the API token and all 'token' and 'auth_token' query
+// parameters and JSON fields in s. The latter carry the token while logging
+// in, before the client knows it.
+func (c *Client) Redact(s string) string {
+	if len(c.apiToken) != 0 {
+		s = strings.ReplaceAll(s, c.apiToken, redacted)
+	}
+
+	s = tokenJSONRegexp.ReplaceAllString(s, "${1}"+redacted+`"`)
+
+	return tokenParamRegexp.ReplaceAllString(s, "${1}"+redacted)
+}
+
+// redactedError keeps the wrapped error for errors.Is and errors.As, but
+// hides the token in its message.
+type redactedError struct {
+	message string
+	err     error
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// redactError masks the token in the message of err. Transport errors
+// contain the URL including the token.
+func (c *Client) redactError(err error) error {
+	var r *redactedError
+
+	if err == nil || errors.As(err, &r) {
+		return err
+	}
+
+	return &redactedError{message: c.Redact(err.Error()), err: err}
+}
+
+// truncate shortens lines longer than the dump body limit.
+func (c *Client) truncate(s string) string {
+	if c.dumpBodyLimit <= 0 {
+		return s
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+
+	for i, line := range lines {
+		text := strings.TrimSuffix(line, "\n")
+
+		if len(text) > c.dumpBodyLimit {
+			lines[i] = fmt.Sprintf("%s... (%d bytes truncated)%s", text[:c.dumpBodyLimit], len(text)-c.dumpBodyLimit, line[len(text):])
+		}
+	}
+
+	return strings.Join(lines, "")
+}
+
+// dump returns the redacted dump of the request and response for error
+// messages.
+func (c *Client) dump(resp *req.Response) string {
+	return c.truncate(c.Redact(resp.Dump()))
+}
+
+// redactingWriter redacts and truncates the debug output line by line, so
+// that a token split across writes is masked, too.
+type redactingWriter struct {
+	mu  sync.Mutex
+	c   *Client
+	w   io.Writer
+	buf []byte
+}
+
+func (rw *redactingWriter) Write(p []byte) (int, error) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	rw.buf = append(rw.buf, p...)
+
+	i := bytes.LastIndexByte(rw.buf, '\n')
+	if i < 0 {
+		return len(p), nil
+	}
+
+	_, err := io.WriteString(rw.w, rw.c.truncate(rw.c.Redact(string(rw.buf[:i+1]))))
+	rw.buf = append(rw.buf[:0], rw.buf[i+1:]...)
+
+	return len(p), err
+}
+
+// Flush writes a final line without newline.
+func (rw *redactingWriter) Flush() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if len(rw.buf) == 0 {
+		return nil
+	}
+
+	_, err := io.WriteString(rw.w, rw.c.truncate(rw.c.Redact(string(rw.buf))))
+	rw.buf = rw.buf[:0]
+
+	return err
+}
+
+// SetDebug enables the debug log and the dump of all requests and responses
+// to w for this client, or disables them if w is nil. The token is masked in
+// the output. The output to the previous writer is flushed.
+func (c *Client) SetDebug(w io.Writer) {
+	_ = c.Close()
+
+	if w == nil {
+		c.debug = nil
+		c.handle.DisableDumpAll()
+		c.handle.DisableDebugLog()
+
+		return
+	}
+
+	c.debug = &redactingWriter{c: c, w: w}
+
+	c.handle.EnableDumpAllTo(c.debug)
+	c.handle.SetLogger(req.NewLogger(c.debug, "", log.LstdFlags))
+	c.handle.EnableDebugLog()
+}
+
+// Close flushes the debug output. The output is written line by line, so
+// the last line would be lost without it.
+func (c *Client) Close() error {
+	if c.debug == nil {
+		return nil
+	}
+
+	return c.debug.Flush()
+}
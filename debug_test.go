@@ -0,0 +1,158 @@
+package goplin
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		in    string
+		want  string
+	}{
+		{
+			name:  "token in query",
+			token: "secret",
+			in:    "GET /notes?token=secret&fields=id HTTP/1.1",
+			want:  "GET /notes?token=REDACTED&fields=id HTTP/1.1",
+		},
+		{
+			name:  "token elsewhere",
+			token: "secret",
+			in:    `{"token":"secret"}`,
+			want:  `{"token":"REDACTED"}`,
+		},
+		{
+			name: "unknown token in query",
+			in:   "http://localhost:41184/notes?fields=id&token=abc123",
+			want: "http://localhost:41184/notes?fields=id&token=REDACTED",
+		},
+		{
+			name: "auth token",
+			in:   "GET /auth/check?auth_token=abc123 HTTP/1.1",
+			want: "GET /auth/check?auth_token=REDACTED HTTP/1.1",
+		},
+		{
+			name: "token at the start of a line",
+			in:   "token=abc123\nnext line",
+			want: "token=REDACTED\nnext line",
+		},
+		{
+			name: "auth token in JSON",
+			in:   `{"auth_token":"abc123"}`,
+			want: `{"auth_token":"REDACTED"}`,
+		},
+		{
+			name: "token in JSON",
+			in:   `{"status": "accepted", "token" : "abc\"123"}`,
+			want: `{"status": "accepted", "token" : "REDACTED"}`,
+		},
+		{
+			name: "empty token in JSON",
+			in:   `{"token":""}`,
+			want: `{"token":""}`,
+		},
+		{
+			name: "other parameters are kept",
+			in:   "/search?query=mytoken=1&type=note",
+			want: "/search?query=mytoken=1&type=note",
+		},
+		{
+			name: "nothing to redact",
+			in:   "Joplin answered with 404",
+			want: "Joplin answered with 404",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{apiToken: tt.token}
+
+			if got := c.Redact(tt.in); got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	c := &Client{apiToken: "secret"}
+	cause := errors.New(`Get "http://localhost:41184/notes?token=secret": connection refused`)
+
+	err := c.redactError(cause)
+
+	if want := `Get "http://localhost:41184/notes?token=REDACTED": connection refused`; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	if !errors.Is(err, cause) {
+		t.Errorf("errors.Is(err, cause) = false, want true")
+	}
+
+	if c.redactError(err) != err {
+		t.Errorf("redactError wrapped an already redacted error")
+	}
+
+	if c.redactError(nil) != nil {
+		t.Errorf("redactError(nil) != nil")
+	}
+}
+
+func TestRedactingWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		limit  int
+		want   string
+	}{
+		{
+			name:   "token split across writes",
+			writes: []string{"GET /notes?tok", "en=sec", "ret HTTP/1.1\n"},
+			want:   "GET /notes?token=REDACTED HTTP/1.1\n",
+		},
+		{
+			name:   "last line without newline",
+			writes: []string{"first\n", "token=secret"},
+			want:   "first\ntoken=REDACTED",
+		},
+		{
+			name:   "token of the login in the response",
+			writes: []string{"GET /auth/check?auth_token=abc HTTP/1.1\n\nHTTP/1.1 200 OK\n", `{"status":"accepted","tok`, `en":"0f9e8d7c"}`},
+			want:   "GET /auth/check?auth_token=REDACTED HTTP/1.1\n\nHTTP/1.1 200 OK\n" + `{"status":"accepted","token":"REDACTED"}`,
+		},
+		{
+			name:   "long lines are truncated",
+			writes: []string{"0123456789\nshort\n", "last line"},
+			limit:  5,
+			want:   "01234... (5 bytes truncated)\nshort\nlast ... (4 bytes truncated)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			c := &Client{apiToken: "secret", dumpBodyLimit: tt.limit}
+			c.debug = &redactingWriter{c: c, w: &out}
+
+			for _, s := range tt.writes {
+				_, err := c.debug.Write([]byte(s))
+				if err != nil {
+					t.Fatalf("Write: %v", err)
+				}
+			}
+
+			err := c.Close()
+			if err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
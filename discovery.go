@@ -0,0 +1,143 @@
+package goplin
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imroc/req/v3"
+)
+
+// PingResponse is the body of the answer of Joplin to '/ping'.
+const PingResponse = "JoplinClipperServer"
+
+// PortStatus is the result of probing a port for the Web Clipper service.
+type PortStatus struct {
+	Port int
+	// Open is true if a program accepts connections on the port.
+	Open bool
+	// Joplin is true if the program answers '/ping' like Joplin.
+	Joplin bool
+	// Response is the start of the answer to '/ping'.
+	Response string
+	Latency  time.Duration
+	Err      error
+}
+
+// Ping probes the port for the Joplin Web Clipper service.
+func Ping(host string, port int, timeout time.Duration) PortStatus {
+	status := PortStatus{Port: port}
+	address := net.JoinHostPort(host, fmt.Sprint(port))
+
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		status.Err = err
+
+		return status
+	}
+
+	conn.Close()
+
+	status.Open = true
+
+	start := time.Now()
+
+	resp, err := req.C().
+		SetUserAgent("goplin").
+		SetTimeout(timeout).
+		R().
+		Get(fmt.Sprintf("http://%s/ping", address))
+	if err != nil {
+		status.Err = err
+
+		return status
+	}
+
+	status.Latency = time.Since(start)
+
+	body := strings.TrimSpace(resp.String())
+	if len(body) > 60 {
+		body = body[:60] + "..."
+	}
+
+	status.Response = body
+	status.Joplin = resp.IsSuccess() && body == PingResponse
+
+	return status
+}
+
+// ScanPorts probes all ports on which Joplin may run. The results are
+// ordered by port.
+func ScanPorts(host string, timeout time.Duration) []PortStatus {
+	statuses := make([]PortStatus, joplinMaxPortNum-joplinMinPortNum+1)
+
+	var wg sync.WaitGroup
+
+	for i := range statuses {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			statuses[i] = Ping(host, joplinMinPortNum+i, timeout)
+		}(i)
+	}
+
+	wg.Wait()
+
+	return statuses
+}
+
+// findPort returns the port of the Joplin Web Clipper service. The cached
+// port is tried first, then all ports are probed at once.
+func findPort(opts Options) (int, error) {
+	timeout := opts.PingTimeout
+	if timeout == 0 {
+		timeout = DefaultPingTimeout
+	}
+
+	if opts.Port != 0 {
+		status := Ping(opts.Host, opts.Port, timeout)
+		if !status.Joplin {
+			return 0, portError(opts.Host, status)
+		}
+
+		return opts.Port, nil
+	}
+
+	if opts.CachedPort != 0 && Ping(opts.Host, opts.CachedPort, timeout).Joplin {
+		return opts.CachedPort, nil
+	}
+
+	var others []string
+
+	for _, status := range ScanPorts(opts.Host, timeout) {
+		if status.Joplin {
+			return status.Port, nil
+		}
+
+		if status.Open {
+			others = append(others, fmt.Sprint(status.Port))
+		}
+	}
+
+	err := fmt.Errorf("could not find the Joplin Web Clipper service on %s, ports %d-%d", opts.Host, joplinMinPortNum, joplinMaxPortNum)
+	if len(others) != 0 {
+		err = fmt.Errorf("%w, ports used by other programs: %s", err, strings.Join(others, ", "))
+	}
+
+	return 0, err
+}
+
+func portError(host string, status PortStatus) error {
+	switch {
+	case status.Open && status.Err == nil:
+		return fmt.Errorf("the program on %s:%d is not the Joplin Web Clipper service, it answered '%s'", host, status.Port, status.Response)
+	case status.Open:
+		return fmt.Errorf("the program on %s:%d is not the Joplin Web Clipper service: %w", host, status.Port, status.Err)
+	}
+
+	return fmt.Errorf("could not connect to the Joplin Web Clipper service on %s:%d: %w", host, status.Port, status.Err)
+}
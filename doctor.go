@@ -0,0 +1,73 @@
+package goplin
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Capability tells whether the Joplin instance supports an endpoint of the
+// Data API.
+type Capability struct {
+	Endpoint  string
+	Supported bool
+}
+
+var capabilityEndpoints = []string{
+	"notes",
+	"folders",
+	"tags",
+	"resources",
+	"search",
+	"events",
+	"revisions",
+	"master_keys",
+}
+
+// Capabilities probes the endpoints of the Data API. Joplin does not report
+// its version, so the supported endpoints tell what the instance can do.
+func (c *Client) Capabilities() ([]Capability, error) {
+	var capabilities []Capability
+
+	for _, endpoint := range capabilityEndpoints {
+		r := c.handle.R().
+			SetQueryParams(map[string]string{
+				"query":  "goplin",
+				"fields": "id",
+				"limit":  "1",
+			})
+
+		_, err := c.send(r, http.MethodGet, "/"+endpoint)
+		if isStatus(err, http.StatusForbidden) {
+			return capabilities, ErrInvalidToken
+		}
+
+		var respErr *ResponseError
+
+		if err != nil && !errors.As(err, &respErr) {
+			return capabilities, err
+		}
+
+		capabilities = append(capabilities, Capability{
+			Endpoint:  endpoint,
+			Supported: err == nil,
+		})
+	}
+
+	return capabilities, nil
+}
+
+// HasNotes reports whether the Joplin instance contains any note.
+func (c *Client) HasNotes() (bool, error) {
+	var result notesResult
+
+	r := c.handle.R().
+		SetQueryParams(map[string]string{
+			"fields": "id",
+			"limit":  "1",
+		}).
+		SetResult(&result)
+
+	_, err := c.send(r, http.MethodGet, "/notes")
+
+	return len(result.Items) != 0, err
+}
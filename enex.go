@@ -0,0 +1,290 @@
+package goplin
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+const enexTimeLayout = "20060102T150405Z"
+
+// Elements which must not get a closing tag in the generated HTML.
+var htmlVoidElements = map[string]bool{
+	"area":   true,
+	"base":   true,
+	"br":     true,
+	"col":    true,
+	"embed":  true,
+	"hr":     true,
+	"img":    true,
+	"input":  true,
+	"link":   true,
+	"meta":   true,
+	"source": true,
+	"track":  true,
+	"wbr":    true,
+}
+
+type ENEXResource struct {
+	Data struct {
+		Encoding string `xml:"encoding,attr"`
+		Value    string `xml:",chardata"`
+	} `xml:"data"`
+	Mime       string `xml:"mime"`
+	Attributes struct {
+		FileName  string `xml:"file-name"`
+		SourceURL string `xml:"source-url"`
+	} `xml:"resource-attributes"`
+}
+
+type ENEXNote struct {
+	Title      string         `xml:"title"`
+	Content    string         `xml:"content"`
+	Created    string         `xml:"created"`
+	Updated    string         `xml:"updated"`
+	Tags       []string       `xml:"tag"`
+	Resources  []ENEXResource `xml:"resource"`
+	Attributes struct {
+		Latitude  float64 `xml:"latitude"`
+		Longitude float64 `xml:"longitude"`
+		Altitude  float64 `xml:"altitude"`
+		Author    string  `xml:"author"`
+		SourceURL string  `xml:"source-url"`
+	} `xml:"note-attributes"`
+}
+
+type enexExport struct {
+	Notes []ENEXNote `xml:"note"`
+}
+
+// ReadENEX reads the notes of an Evernote export file.
+func ReadENEX(r io.Reader) ([]ENEXNote, error) {
+	var export enexExport
+
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+
+	err := decoder.Decode(&export)
+	if err != nil {
+		return nil, err
+	}
+
+	return export.Notes, nil
+}
+
+// Bytes returns the decoded data of the resource.
+func (r ENEXResource) Bytes() ([]byte, error) {
+	if len(r.Data.Encoding) != 0 && r.Data.Encoding != "base64" {
+		return nil, fmt.Errorf("unsupported resource encoding '%s'", r.Data.Encoding)
+	}
+
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(r.Data.Value), ""))
+}
+
+func enexTime(s string) int {
+	t, err := time.Parse(enexTimeLayout, strings.TrimSpace(s))
+	if err != nil {
+		return 0
+	}
+
+	return int(t.UnixMilli())
+}
+
+type enexMedia struct {
+	id       string
+	mime     string
+	filename string
+}
+
+// enmlToHTML converts the ENML content of a note to plain HTML. Media
+// elements are replaced by links to the Joplin resources, keyed by the MD5
+// hash of their data.
+func enmlToHTML(content string, media map[string]enexMedia) (string, error) {
+	var sb strings.Builder
+
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	skip := 0
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if skip > 0 {
+				skip++
+
+				continue
+			}
+
+			switch t.Name.Local {
+			case "en-note":
+				sb.WriteString("<div>")
+			case "en-media":
+				var hash, mimeType string
+
+				for _, attr := range t.Attr {
+					switch attr.Name.Local {
+					case "hash":
+						hash = attr.Value
+					case "type":
+						mimeType = attr.Value
+					}
+				}
+
+				m, ok := media[hash]
+				if !ok {
+					continue
+				}
+
+				if len(mimeType) == 0 {
+					mimeType = m.mime
+				}
+
+				if strings.HasPrefix(mimeType, "image/") {
+					fmt.Fprintf(&sb, `<img src=":/%s" alt="%s">`, m.id, html.EscapeString(m.filename))
+				} else {
+					fmt.Fprintf(&sb, `<a href=":/%s">%s</a>`, m.id, html.EscapeString(m.filename))
+				}
+			case "en-todo":
+				checked := ""
+
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "checked" && attr.Value == "true" {
+						checked = " checked"
+					}
+				}
+
+				fmt.Fprintf(&sb, `<input type="checkbox"%s>`, checked)
+			case "en-crypt":
+				sb.WriteString("[encrypted content]")
+				skip = 1
+			default:
+				sb.WriteString("<" + t.Name.Local)
+
+				for _, attr := range t.Attr {
+					fmt.Fprintf(&sb, ` %s="%s"`, attr.Name.Local, html.EscapeString(attr.Value))
+				}
+
+				sb.WriteString(">")
+			}
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+
+				continue
+			}
+
+			switch t.Name.Local {
+			case "en-note":
+				sb.WriteString("</div>")
+			case "en-media", "en-todo", "en-crypt":
+			default:
+				if !htmlVoidElements[t.Name.Local] {
+					sb.WriteString("</" + t.Name.Local + ">")
+				}
+			}
+		case xml.CharData:
+			if skip == 0 {
+				sb.WriteString(html.EscapeString(string(t)))
+			}
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// ImportENEX creates the given Evernote notes in the notebook with the given
+// ID. Embedded resources are uploaded and tags are created if necessary.
+func (c *Client) ImportENEX(notes []ENEXNote, notebookID string) ([]Note, error) {
+	var created []Note
+
+	for _, enexNote := range notes {
+		media := make(map[string]enexMedia)
+
+		for _, r := range enexNote.Resources {
+			data, err := r.Bytes()
+			if err != nil {
+				return created, fmt.Errorf("note '%s': %w", enexNote.Title, err)
+			}
+
+			sum := md5.Sum(data)
+			hash := hex.EncodeToString(sum[:])
+
+			filename := r.Attributes.FileName
+			if len(filename) == 0 {
+				filename = hash
+			}
+
+			resource, err := c.CreateResource(Resource{Title: filename}, filename, data)
+			if err != nil {
+				return created, err
+			}
+
+			media[hash] = enexMedia{
+				id:       resource.ID,
+				mime:     r.Mime,
+				filename: filename,
+			}
+		}
+
+		body, err := enmlToHTML(enexNote.Content, media)
+		if err != nil {
+			return created, fmt.Errorf("note '%s': %w", enexNote.Title, err)
+		}
+
+		createdTime := enexTime(enexNote.Created)
+		updatedTime := enexTime(enexNote.Updated)
+
+		note, err := c.CreateNoteItem(Note{
+			ParentID:        notebookID,
+			Title:           enexNote.Title,
+			BodyHTML:        body,
+			CreatedTime:     createdTime,
+			UpdatedTime:     updatedTime,
+			UserCreatedTime: createdTime,
+			UserUpdatedTime: updatedTime,
+			Latitude:        enexNote.Attributes.Latitude,
+			Longitude:       enexNote.Attributes.Longitude,
+			Altitude:        enexNote.Attributes.Altitude,
+			Author:          enexNote.Attributes.Author,
+			SourceURL:       enexNote.Attributes.SourceURL,
+		})
+		if err != nil {
+			return created, err
+		}
+
+		for _, title := range enexNote.Tags {
+			tag, err := c.FindOrCreateTag(title)
+			if err != nil {
+				return created, err
+			}
+
+			err = c.AddTagToNote(tag.ID, note)
+			if err != nil {
+				return created, err
+			}
+		}
+
+		created = append(created, note)
+	}
+
+	return created, nil
+}
@@ -0,0 +1,160 @@
+package goplin
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testENEX = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE en-export SYSTEM "http://xml.evernote.com/pub/evernote-export3.dtd">
+<en-export export-date="20220801T120000Z" application="Evernote">
+  <note>
+    <title>Shopping</title>
+    <content><![CDATA[<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE en-note SYSTEM "http://xml.evernote.com/pub/enml2.dtd">
+<en-note><div><en-todo checked="true"/>Milk</div><en-media hash="b10a8db164e0754105b7a99be72e3fe5" type="image/png"/></en-note>]]></content>
+    <created>20220801T101500Z</created>
+    <updated>20220802T101500Z</updated>
+    <tag>home</tag>
+    <tag>todo</tag>
+    <resource>
+      <data encoding="base64">
+SGVsbG8g
+V29ybGQ=
+      </data>
+      <mime>image/png</mime>
+      <resource-attributes>
+        <file-name>list.png</file-name>
+      </resource-attributes>
+    </resource>
+  </note>
+</en-export>
+`
+
+func TestReadENEX(t *testing.T) {
+	notes, err := ReadENEX(strings.NewReader(testENEX))
+	if err != nil {
+		t.Fatalf("ReadENEX: %v", err)
+	}
+
+	if len(notes) != 1 {
+		t.Fatalf("ReadENEX() returned %d notes, want 1", len(notes))
+	}
+
+	note := notes[0]
+
+	if note.Title != "Shopping" {
+		t.Errorf("Title = %q, want %q", note.Title, "Shopping")
+	}
+
+	if !reflect.DeepEqual(note.Tags, []string{"home", "todo"}) {
+		t.Errorf("Tags = %q, want %q", note.Tags, []string{"home", "todo"})
+	}
+
+	if got, want := enexTime(note.Created), 1659348900000; got != want {
+		t.Errorf("enexTime(%q) = %d, want %d", note.Created, got, want)
+	}
+
+	if len(note.Resources) != 1 {
+		t.Fatalf("Resources has %d entries, want 1", len(note.Resources))
+	}
+
+	r := note.Resources[0]
+
+	data, err := r.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+
+	if string(data) != "Hello World" {
+		t.Errorf("Bytes() = %q, want %q", data, "Hello World")
+	}
+
+	sum := md5.Sum(data)
+	hash := hex.EncodeToString(sum[:])
+
+	media := map[string]enexMedia{
+		hash: {id: "0123456789abcdef0123456789abcdef", mime: r.Mime, filename: r.Attributes.FileName},
+	}
+
+	body, err := enmlToHTML(note.Content, media)
+	if err != nil {
+		t.Fatalf("enmlToHTML: %v", err)
+	}
+
+	want := "\n\n" + `<div><div><input type="checkbox" checked>Milk</div><img src=":/0123456789abcdef0123456789abcdef" alt="list.png"></div>`
+
+	if body != want {
+		t.Errorf("enmlToHTML() = %q, want %q", body, want)
+	}
+}
+
+func TestENMLToHTML(t *testing.T) {
+	media := map[string]enexMedia{
+		"aaaa": {id: "11111111111111111111111111111111", mime: "image/jpeg", filename: "photo.jpg"},
+		"bbbb": {id: "22222222222222222222222222222222", mime: "application/pdf", filename: "a & b.pdf"},
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "plain markup",
+			content: `<en-note><p class="x">Hello <b>World</b></p></en-note>`,
+			want:    `<div><p class="x">Hello <b>World</b></p></div>`,
+		},
+		{
+			name:    "image by hash",
+			content: `<en-note><en-media hash="aaaa" type="image/jpeg"/></en-note>`,
+			want:    `<div><img src=":/11111111111111111111111111111111" alt="photo.jpg"></div>`,
+		},
+		{
+			name:    "attachment by hash",
+			content: `<en-note><en-media hash="bbbb"></en-media></en-note>`,
+			want:    `<div><a href=":/22222222222222222222222222222222">a &amp; b.pdf</a></div>`,
+		},
+		{
+			name:    "type of the element wins",
+			content: `<en-note><en-media hash="bbbb" type="image/png"/></en-note>`,
+			want:    `<div><img src=":/22222222222222222222222222222222" alt="a &amp; b.pdf"></div>`,
+		},
+		{
+			name:    "unknown hash is dropped",
+			content: `<en-note>a<en-media hash="cccc" type="image/png"/>b</en-note>`,
+			want:    `<div>ab</div>`,
+		},
+		{
+			name:    "todos",
+			content: `<en-note><en-todo checked="true"/>done<br/><en-todo checked="false"/>open<en-todo/>too</en-note>`,
+			want:    `<div><input type="checkbox" checked>done<br><input type="checkbox">open<input type="checkbox">too</div>`,
+		},
+		{
+			name:    "encrypted content is dropped",
+			content: `<en-note>before<en-crypt cipher="AES" length="128">c2VjcmV0<b>x</b></en-crypt>after</en-note>`,
+			want:    `<div>before[encrypted content]after</div>`,
+		},
+		{
+			name:    "HTML entities",
+			content: `<en-note>a&nbsp;&lt;b&gt;</en-note>`,
+			want:    "<div>a\u00a0&lt;b&gt;</div>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := enmlToHTML(tt.content, media)
+			if err != nil {
+				t.Fatalf("enmlToHTML: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("enmlToHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,556 @@
+package goplin
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+const epubCSS = `body { font-family: serif; line-height: 1.4; }
+img { max-width: 100%; }
+pre { white-space: pre-wrap; font-size: 0.85em; }
+`
+
+// The XML declaration is written separately, html/template would escape it.
+const epubTemplate = `{{define "chapter"}}<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="{{.Language}}" xml:lang="{{.Language}}">
+<head>
+<meta charset="UTF-8"/>
+<title>{{.Title}}</title>
+<link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{.Content}}
+</body>
+</html>
+{{end}}
+{{define "nav"}}<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="{{.Language}}" xml:lang="{{.Language}}">
+<head>
+<meta charset="UTF-8"/>
+<title>{{.Title}}</title>
+</head>
+<body>
+<nav epub:type="toc" id="toc">
+<h1>{{.Title}}</h1>
+{{template "toc" .TOC}}
+</nav>
+</body>
+</html>
+{{end}}
+{{define "toc"}}<ol>
+{{range .}}<li>{{if .Href}}<a href="{{.Href}}">{{.Title}}</a>{{else}}<span>{{.Title}}</span>{{end}}{{if .Children}}
+{{template "toc" .Children}}{{end}}</li>
+{{end}}</ol>{{end}}
+{{define "opf"}}<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="{{.Language}}">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:identifier id="book-id">{{.Identifier}}</dc:identifier>
+<dc:title>{{.Title}}</dc:title>
+<dc:language>{{.Language}}</dc:language>
+{{if .Author}}<dc:creator>{{.Author}}</dc:creator>
+{{end}}<meta property="dcterms:modified">{{.Modified}}</meta>
+</metadata>
+<manifest>
+<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+<item id="style" href="style.css" media-type="text/css"/>
+{{range .Manifest}}<item id="{{.ID}}" href="{{.Href}}" media-type="{{.MediaType}}"/>
+{{end}}</manifest>
+<spine>
+{{range .Spine}}<itemref idref="{{.}}"/>
+{{end}}</spine>
+</package>
+{{end}}
+`
+
+// EPUBOptions controls the metadata and the chapter order of an EPUB.
+type EPUBOptions struct {
+	Title    string
+	Author   string
+	Language string
+	OrderBy  string
+	OrderDir string
+	// Warnings receives a line for every link which is dropped.
+	Warnings io.Writer
+}
+
+type epubTOCEntry struct {
+	Title    string
+	Href     string
+	Children []*epubTOCEntry
+}
+
+type epubManifestItem struct {
+	ID        string
+	Href      string
+	MediaType string
+}
+
+type epubBook struct {
+	client   *Client
+	opts     EPUBOptions
+	chapters map[string]bool
+	images   map[string]string
+	files    map[string][]byte
+	manifest []epubManifestItem
+	spine    []string
+	tmpl     *template.Template
+}
+
+// SortNotes sorts notes by the field with the given JSON name, e.g.
+// "title" or "updated_time". The direction is either "ASC" or "DESC".
+func SortNotes(notes []Note, field string, dir string) {
+	index := -1
+
+	t := reflect.TypeOf(Note{})
+
+	for i := 0; i < t.NumField(); i++ {
+		if jsonKey(t.Field(i)) == field {
+			index = i
+		}
+	}
+
+	if index < 0 {
+		return
+	}
+
+	desc := strings.EqualFold(dir, "DESC")
+
+	less := func(i, j int) bool {
+		a := reflect.ValueOf(notes[i]).Field(index)
+		b := reflect.ValueOf(notes[j]).Field(index)
+
+		switch a.Kind() {
+		case reflect.Int:
+			return a.Int() < b.Int()
+		case reflect.Float64:
+			return a.Float() < b.Float()
+		default:
+			return strings.ToLower(a.String()) < strings.ToLower(b.String())
+		}
+	}
+
+	sort.SliceStable(notes, func(i, j int) bool {
+		if desc {
+			return less(j, i)
+		}
+
+		return less(i, j)
+	})
+}
+
+// xhtmlContent renders the HTML of a note as well-formed XHTML. Headings get
+// IDs, so that they can be referenced from the table of contents.
+func xhtmlContent(content string, chapter string) (string, []*epubTOCEntry, error) {
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var headings []*epubTOCEntry
+
+	count := 0
+
+	var walk func(n *html.Node)
+
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; {
+			next := child.NextSibling
+
+			if child.Type == html.ElementNode && (child.DataAtom == atom.Script || child.DataAtom == atom.Style) {
+				n.RemoveChild(child)
+			} else {
+				walk(child)
+			}
+
+			child = next
+		}
+
+		if n.Type != html.ElementNode || (n.DataAtom != atom.H1 && n.DataAtom != atom.H2 && n.DataAtom != atom.H3) {
+			return
+		}
+
+		id := ""
+
+		for _, attr := range n.Attr {
+			if attr.Key == "id" {
+				id = attr.Val
+			}
+		}
+
+		if len(id) == 0 {
+			count++
+			id = fmt.Sprintf("heading-%d", count)
+			n.Attr = append(n.Attr, html.Attribute{Key: "id", Val: id})
+		}
+
+		headings = append(headings, &epubTOCEntry{
+			Title: textContent(n),
+			Href:  chapter + "#" + id,
+		})
+	}
+
+	var buf bytes.Buffer
+
+	for _, n := range nodes {
+		if n.Type == html.ElementNode && (n.DataAtom == atom.Script || n.DataAtom == atom.Style) {
+			continue
+		}
+
+		walk(n)
+
+		err = html.Render(&buf, n)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	return buf.String(), headings, nil
+}
+
+func textContent(n *html.Node) string {
+	var sb strings.Builder
+
+	var walk func(n *html.Node)
+
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+
+	walk(n)
+
+	return strings.TrimSpace(sb.String())
+}
+
+func (b *epubBook) warn(format string, a ...interface{}) {
+	if b.opts.Warnings != nil {
+		fmt.Fprintf(b.opts.Warnings, "Warning: "+format+"\n", a...)
+	}
+}
+
+func (b *epubBook) addChapter(note Note) (*epubTOCEntry, error) {
+	href := note.ID + ".xhtml"
+
+	// Image resources are embedded, links to other chapters are kept and
+	// all other Joplin links are dropped as they can't be resolved.
+	var retErr error
+
+	note.Body = itemLinkRegexp.ReplaceAllStringFunc(note.Body, func(link string) string {
+		id := strings.TrimPrefix(link, ":/")
+
+		if b.chapters[id] {
+			return id + ".xhtml"
+		}
+
+		if name, ok := b.images[id]; ok {
+			return name
+		}
+
+		resource, err := b.client.GetResource(id, AllResourceFields)
+		if isNotFound(err) {
+			b.warn("note '%s': dropped the link to %s, which is not part of the book", note.Title, id)
+
+			return "#"
+		}
+
+		if err != nil {
+			b.warn("note '%s': dropped the link to resource %s: %v", note.Title, id, err)
+
+			return "#"
+		}
+
+		if !strings.HasPrefix(resource.Mime, "image/") {
+			b.warn("note '%s': dropped the link to '%s', only images are embedded", note.Title, resource.Title)
+
+			return "#"
+		}
+
+		data, err := b.client.GetResourceFile(id)
+		if err != nil {
+			retErr = err
+
+			return link
+		}
+
+		name := "images/" + resourceFilename(resource)
+
+		b.images[id] = name
+		b.files[name] = data
+		b.manifest = append(b.manifest, epubManifestItem{
+			ID:        "img-" + id,
+			Href:      name,
+			MediaType: resource.Mime,
+		})
+
+		return name
+	})
+
+	if retErr != nil {
+		return nil, retErr
+	}
+
+	content, err := RenderNoteHTML(note)
+	if err != nil {
+		return nil, err
+	}
+
+	content, headings, err := xhtmlContent(content, href)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBufferString(xml.Header)
+
+	err = b.tmpl.ExecuteTemplate(buf, "chapter", map[string]interface{}{
+		"Language": b.opts.Language,
+		"Title":    note.Title,
+		"Content":  template.HTML(content),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b.files[href] = buf.Bytes()
+	b.manifest = append(b.manifest, epubManifestItem{
+		ID:        "note-" + note.ID,
+		Href:      href,
+		MediaType: "application/xhtml+xml",
+	})
+	b.spine = append(b.spine, "note-"+note.ID)
+
+	return &epubTOCEntry{
+		Title:    note.Title,
+		Href:     href,
+		Children: headings,
+	}, nil
+}
+
+// ExportEPUB writes the selected notes as an EPUB 3 book. If the selection
+// contains a notebook, the table of contents follows the notebook tree,
+// otherwise the chapters form a flat list. Within a notebook the chapters
+// are ordered by the field given in the options.
+func (c *Client) ExportEPUB(w io.Writer, sel Selection, opts EPUBOptions) error {
+	if len(opts.Language) == 0 {
+		opts.Language = "en"
+	}
+
+	if len(opts.OrderBy) == 0 {
+		opts.OrderBy = "order"
+		opts.OrderDir = "DESC"
+	}
+
+	notes, err := c.SelectNotes(sel, AllNoteFields)
+	if err != nil {
+		return err
+	}
+
+	if len(notes) == 0 {
+		return fmt.Errorf("no notes selected")
+	}
+
+	SortNotes(notes, opts.OrderBy, opts.OrderDir)
+
+	b := epubBook{
+		client:   c,
+		opts:     opts,
+		chapters: make(map[string]bool),
+		images:   make(map[string]string),
+		files:    make(map[string][]byte),
+		tmpl:     template.Must(template.New("epub").Parse(epubTemplate)),
+	}
+
+	for _, note := range notes {
+		b.chapters[note.ID] = true
+	}
+
+	var toc []*epubTOCEntry
+
+	if len(sel.Notebook) != 0 {
+		root, err := c.FindNotebook(sel.Notebook)
+		if err != nil {
+			return err
+		}
+
+		if len(b.opts.Title) == 0 {
+			b.opts.Title = root.Title
+		}
+
+		notebooks, err := c.GetAllNotebooks("id,parent_id,title", "title", "ASC")
+		if err != nil {
+			return err
+		}
+
+		var addNotebook func(id string) ([]*epubTOCEntry, error)
+
+		addNotebook = func(id string) ([]*epubTOCEntry, error) {
+			var entries []*epubTOCEntry
+
+			for _, note := range notes {
+				if note.ParentID != id {
+					continue
+				}
+
+				entry, err := b.addChapter(note)
+				if err != nil {
+					return nil, err
+				}
+
+				entries = append(entries, entry)
+			}
+
+			if !sel.Recursive {
+				return entries, nil
+			}
+
+			for _, notebook := range notebooks {
+				if notebook.ParentID != id {
+					continue
+				}
+
+				children, err := addNotebook(notebook.ID)
+				if err != nil {
+					return nil, err
+				}
+
+				if len(children) != 0 {
+					entries = append(entries, &epubTOCEntry{
+						Title:    notebook.Title,
+						Children: children,
+					})
+				}
+			}
+
+			return entries, nil
+		}
+
+		toc, err = addNotebook(root.ID)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, note := range notes {
+			entry, err := b.addChapter(note)
+			if err != nil {
+				return err
+			}
+
+			toc = append(toc, entry)
+		}
+	}
+
+	if len(b.opts.Title) == 0 {
+		b.opts.Title = "Joplin Notes"
+	}
+
+	zw := zip.NewWriter(w)
+
+	// The mimetype has to be the first entry and must not be compressed.
+	mw, err := zw.CreateHeader(&zip.FileHeader{
+		Name:   "mimetype",
+		Method: zip.Store,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = mw.Write([]byte("application/epub+zip"))
+	if err != nil {
+		return err
+	}
+
+	writeFile := func(name string, data []byte) error {
+		fw, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+
+		_, err = fw.Write(data)
+
+		return err
+	}
+
+	err = writeFile("META-INF/container.xml", []byte(epubContainer))
+	if err != nil {
+		return err
+	}
+
+	buf := bytes.NewBufferString(xml.Header)
+
+	err = b.tmpl.ExecuteTemplate(buf, "nav", map[string]interface{}{
+		"Language": b.opts.Language,
+		"Title":    b.opts.Title,
+		"TOC":      toc,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeFile("OEBPS/nav.xhtml", buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	id := NewItemID()
+
+	buf = bytes.NewBufferString(xml.Header)
+
+	err = b.tmpl.ExecuteTemplate(buf, "opf", map[string]interface{}{
+		"Identifier": fmt.Sprintf("urn:uuid:%s-%s-%s-%s-%s", id[0:8], id[8:12], id[12:16], id[16:20], id[20:32]),
+		"Language":   b.opts.Language,
+		"Title":      b.opts.Title,
+		"Author":     b.opts.Author,
+		"Modified":   time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		"Manifest":   b.manifest,
+		"Spine":      b.spine,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeFile("OEBPS/content.opf", buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	err = writeFile("OEBPS/style.css", []byte(epubCSS))
+	if err != nil {
+		return err
+	}
+
+	for _, item := range b.manifest {
+		err = writeFile("OEBPS/"+item.Href, b.files[item.Href])
+		if err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
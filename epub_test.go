@@ -0,0 +1,233 @@
+package goplin
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/imroc/req/v3"
+)
+
+func TestSortNotes(t *testing.T) {
+	notes := []Note{
+		{ID: "a", Title: "banana", UpdatedTime: 3, Order: 1.5},
+		{ID: "b", Title: "Apple", UpdatedTime: 1, Order: 3},
+		{ID: "c", Title: "cherry", UpdatedTime: 2, Order: 2},
+		{ID: "d", Title: "apple", UpdatedTime: 2, Order: 0.5},
+	}
+
+	tests := []struct {
+		name  string
+		field string
+		dir   string
+		want  []string
+	}{
+		{name: "title ascending", field: "title", dir: "ASC", want: []string{"b", "d", "a", "c"}},
+		{name: "title descending", field: "title", dir: "DESC", want: []string{"c", "a", "b", "d"}},
+		{name: "integer field", field: "updated_time", dir: "ASC", want: []string{"b", "c", "d", "a"}},
+		{name: "integer field descending", field: "updated_time", dir: "desc", want: []string{"a", "c", "d", "b"}},
+		{name: "float field", field: "order", dir: "DESC", want: []string{"b", "c", "a", "d"}},
+		{name: "unknown field", field: "nope", dir: "ASC", want: []string{"a", "b", "c", "d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted := append([]Note(nil), notes...)
+
+			SortNotes(sorted, tt.field, tt.dir)
+
+			var got []string
+
+			for _, note := range sorted {
+				got = append(got, note.ID)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortNotes(%s, %s) = %v, want %v", tt.field, tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXHTMLContent(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		want         string
+		wantHeadings []epubTOCEntry
+	}{
+		{
+			name:    "no headings",
+			content: "<p>text<br>more</p>",
+			want:    "<p>text<br/>more</p>",
+		},
+		{
+			name:    "headings get IDs",
+			content: "<h1>One</h1><p>x</p><h2>Two <em>b</em></h2><h4>Four</h4>",
+			want:    `<h1 id="heading-1">One</h1><p>x</p><h2 id="heading-2">Two <em>b</em></h2><h4>Four</h4>`,
+			wantHeadings: []epubTOCEntry{
+				{Title: "One", Href: "c.xhtml#heading-1"},
+				{Title: "Two b", Href: "c.xhtml#heading-2"},
+			},
+		},
+		{
+			name:    "existing IDs are kept",
+			content: `<h3 id="setup">Setup</h3>`,
+			want:    `<h3 id="setup">Setup</h3>`,
+			wantHeadings: []epubTOCEntry{
+				{Title: "Setup", Href: "c.xhtml#setup"},
+			},
+		},
+		{
+			name:    "scripts and styles are removed",
+			content: "<script>alert(1)</script><p>a<style>p {}</style></p>",
+			want:    "<p>a</p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, headings, err := xhtmlContent(tt.content, "c.xhtml")
+			if err != nil {
+				t.Fatalf("xhtmlContent: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("xhtmlContent(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+
+			var gotHeadings []epubTOCEntry
+
+			for _, h := range headings {
+				gotHeadings = append(gotHeadings, *h)
+			}
+
+			if !reflect.DeepEqual(gotHeadings, tt.wantHeadings) {
+				t.Errorf("headings = %+v, want %+v", gotHeadings, tt.wantHeadings)
+			}
+		})
+	}
+}
+
+func TestEPUBNav(t *testing.T) {
+	tmpl := template.Must(template.New("epub").Parse(epubTemplate))
+
+	toc := []*epubTOCEntry{
+		{Title: "Intro", Href: "a.xhtml", Children: []*epubTOCEntry{
+			{Title: "Setup", Href: "a.xhtml#setup"},
+		}},
+		{Title: "Projects", Children: []*epubTOCEntry{
+			{Title: "Q&A", Href: "b.xhtml"},
+		}},
+	}
+
+	var buf bytes.Buffer
+
+	err := tmpl.ExecuteTemplate(&buf, "toc", toc)
+	if err != nil {
+		t.Fatalf("ExecuteTemplate: %v", err)
+	}
+
+	want := `<ol>
+<li><a href="a.xhtml">Intro</a>
+<ol>
+<li><a href="a.xhtml#setup">Setup</a></li>
+</ol></li>
+<li><span>Projects</span>
+<ol>
+<li><a href="b.xhtml">Q&amp;A</a></li>
+</ol></li>
+</ol>`
+
+	if got := buf.String(); got != want {
+		t.Errorf("toc = %q, want %q", got, want)
+	}
+}
+
+func TestEPUBChapter(t *testing.T) {
+	const (
+		noteID    = "11111111111111111111111111111111"
+		chapterID = "22222222222222222222222222222222"
+		imageID   = "33333333333333333333333333333333"
+		pdfID     = "44444444444444444444444444444444"
+		missingID = "55555555555555555555555555555555"
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/resources/" + pdfID:
+			fmt.Fprintf(w, `{"id":"%s","title":"report.pdf","mime":"application/pdf"}`, pdfID)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":"Not Found"}`)
+		}
+	}))
+	defer server.Close()
+
+	addr := server.Listener.Addr().(*net.TCPAddr)
+
+	var warnings bytes.Buffer
+
+	b := &epubBook{
+		client:   &Client{handle: req.C(), host: addr.IP.String(), port: addr.Port},
+		opts:     EPUBOptions{Language: "en", Warnings: &warnings},
+		chapters: map[string]bool{noteID: true, chapterID: true},
+		images:   map[string]string{imageID: "images/photo.png"},
+		files:    make(map[string][]byte),
+		tmpl:     template.Must(template.New("epub").Parse(epubTemplate)),
+	}
+
+	entry, err := b.addChapter(Note{
+		ID:    noteID,
+		Title: "Intro",
+		Body: "# Setup\n\nSee [next](:/" + chapterID + ") and ![photo](:/" + imageID + ").\n\n" +
+			"## Files\n\n[report](:/" + pdfID + ") [elsewhere](:/" + missingID + ")\n",
+	})
+	if err != nil {
+		t.Fatalf("addChapter: %v", err)
+	}
+
+	want := &epubTOCEntry{
+		Title: "Intro",
+		Href:  noteID + ".xhtml",
+		Children: []*epubTOCEntry{
+			{Title: "Setup", Href: noteID + ".xhtml#heading-1"},
+			{Title: "Files", Href: noteID + ".xhtml#heading-2"},
+		},
+	}
+
+	if !reflect.DeepEqual(entry, want) {
+		t.Errorf("addChapter() = %+v, want %+v", entry, want)
+	}
+
+	chapter := string(b.files[noteID+".xhtml"])
+
+	for _, s := range []string{
+		`<a href="` + chapterID + `.xhtml">next</a>`,
+		`<img src="images/photo.png" alt="photo"/>`,
+		`<a href="#">report</a>`,
+		`<a href="#">elsewhere</a>`,
+	} {
+		if !strings.Contains(chapter, s) {
+			t.Errorf("chapter does not contain %q:\n%s", s, chapter)
+		}
+	}
+
+	wantWarnings := "Warning: note 'Intro': dropped the link to 'report.pdf', only images are embedded\n" +
+		"Warning: note 'Intro': dropped the link to " + missingID + ", which is not part of the book\n"
+
+	if warnings.String() != wantWarnings {
+		t.Errorf("warnings = %q, want %q", warnings.String(), wantWarnings)
+	}
+
+	if !reflect.DeepEqual(b.spine, []string{"note-" + noteID}) {
+		t.Errorf("spine = %v, want %v", b.spine, []string{"note-" + noteID})
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
@@ -17,9 +19,55 @@ import (
 )
 
 type Client struct {
-	handle   *req.Client
-	port     int
-	apiToken string
+	handle        *req.Client
+	host          string
+	port          int
+	apiToken      string
+	dumpBodyLimit int
+	retries       int
+	retryWait     time.Duration
+	bulkWorkers   int
+	debug         *redactingWriter
+}
+
+// Options configure the connection to the Joplin Web Clipper service.
+type Options struct {
+	// Host defaults to "localhost".
+	Host string
+	// Port is searched for if zero.
+	Port int
+	// CachedPort is tried first when searching for the port, e.g. the port
+	// found last time.
+	CachedPort int
+	// PingTimeout limits the probing of a port, DefaultPingTimeout is used
+	// if zero.
+	PingTimeout time.Duration
+	// APIToken is sent with every request.
+	APIToken string
+	// Authorize requests a token from Joplin if APIToken is empty, which the
+	// user has to accept in the Joplin app.
+	Authorize bool
+	// Debug receives the debug log and the dumps of all requests and
+	// responses, with the token masked.
+	Debug io.Writer
+	// DumpBodyLimit truncates longer lines of dumps in the debug output and
+	// in error messages. Zero means no limit.
+	DumpBodyLimit int
+	// Retries is the number of retries of idempotent requests which failed
+	// with a connection error or a server error.
+	Retries int
+	// RetryWait is the wait before the first retry, DefaultRetryWait is used
+	// if zero.
+	RetryWait time.Duration
+	// MaxConcurrentRequests limits the number of requests sent at the same
+	// time. Zero means no limit.
+	MaxConcurrentRequests int
+	// RateLimit limits the number of requests per second. Zero means no
+	// limit.
+	RateLimit float64
+	// BulkWorkers is the number of requests the bulk functions like GetNotes
+	// send at the same time, DefaultBulkWorkers is used if zero.
+	BulkWorkers int
 }
 
 type Tag struct {
@@ -118,6 +166,15 @@ type Event struct {
 	BeforeChangeItem string `json:"before_change_item,omitempty"`
 }
 
+type NoteTag struct {
+	ID          string `json:"id"`
+	NoteID      string `json:"note_id"`
+	TagID       string `json:"tag_id"`
+	CreatedTime int    `json:"created_time,omitempty"`
+	UpdatedTime int    `json:"updated_time,omitempty"`
+	IsShared    int    `json:"is_shared,omitempty"`
+}
+
 type tagsResult struct {
 	Items   []Tag `json:"items"`
 	HasMore bool  `json:"has_more"`
@@ -155,11 +212,18 @@ type CellFormat struct {
 }
 
 const (
-	joplinMinPortNum   = 41184
-	joplinMaxPortNum   = 41194
-	retriesGetApiToken = 20
+	joplinMinPortNum = 41184
+	joplinMaxPortNum = 41194
 )
 
+// DefaultPingTimeout limits the probing of a port while searching for the
+// Joplin Web Clipper service.
+const DefaultPingTimeout = time.Second
+
+// DefaultAuthWait is how long New waits for the user to grant access in the
+// Joplin app.
+const DefaultAuthWait = 20 * time.Second
+
 const (
 	ItemTypeName               = "name"
 	ItemTypeFolder             = "folder"
@@ -592,227 +656,167 @@ var SearchFormats = map[string]CellFormat{
 }
 
 func New(apiToken string) (*Client, error) {
-	var retErr error
-
-	joplinPortFound := false
+	return NewWithOptions(Options{APIToken: apiToken, Authorize: true, Retries: DefaultRetries})
+}
 
+// NewWithOptions connects to the Joplin Web Clipper service as configured by
+// the options.
+func NewWithOptions(opts Options) (*Client, error) {
 	// In production, create a client explicitly and reuse it to send all requests
 	// Use C() to create a client and set with chainable client settings.
 	client := req.C().
 		SetUserAgent("goplin").
 		SetTimeout(5 * time.Second)
 
-	newClient := Client{
-		handle:   client,
-		port:     0,
-		apiToken: apiToken,
+	if len(opts.Host) == 0 {
+		opts.Host = "localhost"
 	}
 
-	for i := joplinMinPortNum; i <= joplinMaxPortNum; i++ {
-		// Use R() to create a request and set with chainable request settings.
-		resp, err := client.R(). // Use R() to create a request and set with chainable request settings.
-						EnableDump(). // Enable dump at request level to help troubleshoot, log content only when an unexpected exception occurs.
-						Get(fmt.Sprintf("http://localhost:%d/ping", i))
-		if err != nil {
-			retErr = err
-			continue
-		}
+	if opts.RetryWait == 0 {
+		opts.RetryWait = DefaultRetryWait
+	}
 
-		if resp.IsError() {
-			retErr = err
-			continue
-		}
+	if opts.MaxConcurrentRequests > 0 || opts.RateLimit > 0 {
+		client.WrapRoundTripFunc(newLimiter(opts.MaxConcurrentRequests, opts.RateLimit).wrap)
+	}
 
-		if resp.IsSuccess() {
-			newClient.port = i
-
-			if len(apiToken) == 0 {
-				authToken, err := newClient.getAuthToken()
-				if err != nil {
-					retErr = err
-					break
-				}
-
-				newClient.apiToken, err = newClient.getApiToken(authToken)
-				if err != nil {
-					retErr = err
-					break
-				}
-			}
+	newClient := Client{
+		handle:        client,
+		host:          opts.Host,
+		port:          0,
+		apiToken:      opts.APIToken,
+		dumpBodyLimit: opts.DumpBodyLimit,
+		retries:       opts.Retries,
+		retryWait:     opts.RetryWait,
+		bulkWorkers:   opts.BulkWorkers,
+	}
 
-			joplinPortFound = true
+	if opts.Debug != nil {
+		newClient.SetDebug(opts.Debug)
+	}
 
-			break
-		}
+	port, err := findPort(opts)
+	if err != nil {
+		_ = newClient.Close()
+
+		return nil, err
 	}
 
-	if !joplinPortFound {
-		return nil, retErr
+	newClient.port = port
+
+	if len(opts.APIToken) == 0 && opts.Authorize {
+		err = newClient.Authorize(DefaultAuthWait, nil)
+		if err != nil {
+			_ = newClient.Close()
+
+			return nil, err
+		}
 	}
 
 	return &newClient, nil
 }
 
 func (c *Client) getAuthToken() (string, error) {
-	var token string
-
 	var result struct {
 		AuthToken string `json:"auth_token"`
 	}
 
-	resp, err := c.handle.R().
-		SetResult(&result).
-		Post(fmt.Sprintf("http://localhost:%d/auth", c.port))
+	_, err := c.send(c.handle.R().SetResult(&result), http.MethodPost, "/auth")
 	if err != nil {
-		return token, err
+		return "", err
 	}
 
-	if resp.IsError() {
-		// Handle response.
-		err = fmt.Errorf("got error response, raw dump:\n%s", resp.Dump())
+	return result.AuthToken, nil
+}
 
-		return token, err
+// Authorize requests an API token from Joplin and waits up to wait for the
+// user to grant access in the Joplin app. If progress is set, it is called
+// every second with the remaining time.
+func (c *Client) Authorize(wait time.Duration, progress func(remaining time.Duration)) error {
+	authToken, err := c.getAuthToken()
+	if err != nil {
+		return err
 	}
 
-	if resp.IsSuccess() {
-		return result.AuthToken, nil
+	apiToken, err := c.getApiToken(authToken, wait, progress)
+	if err != nil {
+		return err
 	}
 
-	// Handle response.
-	err = fmt.Errorf("got unexpected response, raw dump:\n%s", resp.Dump())
+	c.apiToken = apiToken
 
-	return token, err
+	return nil
 }
 
-func (c *Client) getApiToken(authToken string) (string, error) {
-	var retErr error
-
+func (c *Client) getApiToken(authToken string, wait time.Duration, progress func(remaining time.Duration)) (string, error) {
 	var result struct {
 		Status   string `json:"status"`
 		ApiToken string `json:"token,omitempty"`
 	}
 
-	retries := 0
-	receivedApiToken := false
+	deadline := time.Now().Add(wait)
 
 	for {
-		resp, err := c.handle.R().
+		r := c.handle.R().
 			SetQueryParam("auth_token", authToken).
-			SetResult(&result).
-			SetError(&result).
-			Get(fmt.Sprintf("http://localhost:%d/auth/check", c.port))
-		if err != nil {
-			retErr = err
-			break
-		}
-
-		if resp.IsError() {
-			// Handle response.
-			err = fmt.Errorf("got error response, raw dump:\n%s", resp.Dump())
-			retErr = err
+			SetResult(&result)
 
-			break
+		_, err := c.send(r, http.MethodGet, "/auth/check")
+		if err != nil {
+			return "", err
 		}
 
-		if resp.IsSuccess() {
-			if result.Status == "accepted" {
-				receivedApiToken = true
-
-				break
-			} else if result.Status == "rejected" {
-				err = errors.New("request rejected")
-				retErr = err
-
-				break
-			} else if result.Status == "waiting" {
-				retries++
-
-				if retries < retriesGetApiToken {
-					time.Sleep(time.Second)
-
-					continue
-				}
-
-				retErr = fmt.Errorf("could not get an answer from user")
+		switch result.Status {
+		case "accepted":
+			return result.ApiToken, nil
+		case "rejected":
+			return "", errors.New("request rejected")
+		case "waiting":
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return "", fmt.Errorf("could not get an answer from user")
+			}
 
-				break
+			if progress != nil {
+				progress(remaining)
 			}
-		}
-	}
 
-	if receivedApiToken {
-		return result.ApiToken, nil
+			time.Sleep(time.Second)
+		default:
+			return "", fmt.Errorf("got unexpected authorisation status '%s'", result.Status)
+		}
 	}
-
-	return "", retErr
 }
 
 func (c *Client) GetTag(id string, fields string) (Tag, error) {
 	var tag Tag
 
-	resp, err := c.handle.R().
+	r := c.handle.R().
 		SetPathParam("id", id).
-		SetQueryParam("token", c.apiToken).
 		SetQueryParam("fields", fields).
-		SetResult(&tag).
-		SetError(&tag).
-		Get(fmt.Sprintf("http://localhost:%d/tags/{id}", c.port))
-	if err != nil {
-		return tag, err
-	}
-
-	if resp.IsError() {
-		if resp.StatusCode == 404 {
-			err = fmt.Errorf("could not find tag with IDs '%s", id)
-
-		} else {
-			err = fmt.Errorf("got error response, raw dump:\n%s", resp.Dump())
-		}
-
-		return tag, err
-	}
+		SetResult(&tag)
 
-	if resp.IsSuccess() {
-		return tag, nil
+	_, err := c.send(r, http.MethodGet, "/tags/{id}")
+	if isNotFound(err) {
+		return tag, &NotFoundError{Type: "tag", ID: id}
 	}
 
-	// Handle response.
-	err = fmt.Errorf("got unexpected response, raw dump:\n%s", resp.Dump())
-
 	return tag, err
 }
 
 func (c *Client) GetNote(id string, fields string) (Note, error) {
 	var note Note
 
-	resp, err := c.handle.R().
+	r := c.handle.R().
 		SetPathParam("id", id).
-		SetQueryParam("token", c.apiToken).
 		SetQueryParam("fields", fields).
-		SetResult(&note).
-		SetError(&note).
-		Get(fmt.Sprintf("http://localhost:%d/notes/{id}", c.port))
-	if err != nil {
-		return note, err
-	}
+		SetResult(&note)
 
-	if resp.IsError() {
-		if resp.StatusCode == 404 {
-			err = fmt.Errorf("could not find note with ID '%s", id)
-		} else {
-			err = fmt.Errorf("got error response, raw dump:\n%s", resp.Dump())
-		}
-
-		return note, err
-	}
-
-	if resp.IsSuccess() {
-		return note, nil
+	_, err := c.send(r, http.MethodGet, "/notes/{id}")
+	if isNotFound(err) {
+		return note, &NotFoundError{Type: "note", ID: id}
 	}
 
-	// Handle response.
-	err = fmt.Errorf("got unexpected response, raw dump:\n%s", resp.Dump())
-
 	return note, err
 }
 
@@ -823,7 +827,6 @@ func (c *Client) GetNotesByTag(id string, orderBy string, orderDir string) ([]No
 	page := 1
 
 	queryParams := map[string]string{
-		"token":  c.apiToken,
 		"fields": "id,parent_id,title",
 		"page":   strconv.Itoa(page),
 	}
@@ -837,46 +840,29 @@ func (c *Client) GetNotesByTag(id string, orderBy string, orderDir string) ([]No
 	}
 
 	for {
-		resp, err := c.handle.R().
+		r := c.handle.R().
 			SetPathParam("id", id).
 			SetQueryParams(queryParams).
-			SetResult(&result).
-			SetError(&result).
-			Get(fmt.Sprintf("http://localhost:%d/tags/{id}/notes", c.port))
-		if err != nil {
-			return notes, err
-		}
+			SetResult(&result)
 
-		if resp.IsError() {
-			if resp.StatusCode == 404 {
-				err = fmt.Errorf("could not find note with IDs '%s", id)
-			} else {
-				err = fmt.Errorf("got error response, raw dump:\n%s", resp.Dump())
+		_, err := c.send(r, http.MethodGet, "/tags/{id}/notes")
+		if err != nil {
+			if isNotFound(err) {
+				return notes, &NotFoundError{Type: "tag", ID: id}
 			}
 
 			return notes, err
 		}
 
-		if resp.IsSuccess() {
-			for _, note := range result.Items {
-				notes = append(notes, note)
-			}
-
-			if result.HasMore {
-				page++
+		notes = append(notes, result.Items...)
 
-				queryParams["page"] = strconv.Itoa(page)
-
-				continue
-			} else {
-				return notes, nil
-			}
+		if !result.HasMore {
+			return notes, nil
 		}
 
-		// Handle response.
-		err = fmt.Errorf("got unexpected response, raw dump:\n%s", resp.Dump())
+		page++
 
-		return notes, err
+		queryParams["page"] = strconv.Itoa(page)
 	}
 }
 
@@ -887,7 +873,6 @@ func (c *Client) GetAllNotes(fields string, orderBy string, orderDir string) ([]
 	page := 1
 
 	queryParams := map[string]string{
-		"token":  c.apiToken,
 		"fields": fields,
 		"page":   strconv.Itoa(page),
 	}
@@ -901,42 +886,24 @@ func (c *Client) GetAllNotes(fields string, orderBy string, orderDir string) ([]
 	}
 
 	for {
-		resp, err := c.handle.R().
+		r := c.handle.R().
 			SetQueryParams(queryParams).
-			SetResult(&result).
-			SetError(&result).
-			Get(fmt.Sprintf("http://localhost:%d/notes", c.port))
-		if err != nil {
-			return notes, err
-		}
-
-		if resp.IsError() {
-			// handle response.
-			err = fmt.Errorf("got error response, raw dump:\n%s", resp.Dump())
+			SetResult(&result)
 
+		_, err := c.send(r, http.MethodGet, "/notes")
+		if err != nil {
 			return notes, err
 		}
 
-		if resp.IsSuccess() {
-			for _, note := range result.Items {
-				notes = append(notes, note)
-			}
-
-			if result.HasMore {
-				page++
+		notes = append(notes, result.Items...)
 
-				queryParams["page"] = strconv.Itoa(page)
-
-				continue
-			} else {
-				return notes, nil
-			}
+		if !result.HasMore {
+			return notes, nil
 		}
 
-		// Handle response.
-		err = fmt.Errorf("got unexpected response, raw dump:\n%s", resp.Dump())
+		page++
 
-		return notes, err
+		queryParams["page"] = strconv.Itoa(page)
 	}
 }
 
@@ -947,7 +914,6 @@ func (c *Client) GetNotesInNotebook(id string, fields string, orderBy string, or
 	page := 1
 
 	queryParams := map[string]string{
-		"token":  c.apiToken,
 		"fields": fields,
 		"page":   strconv.Itoa(page),
 	}
@@ -961,43 +927,29 @@ func (c *Client) GetNotesInNotebook(id string, fields string, orderBy string, or
 	}
 
 	for {
-		resp, err := c.handle.R().
+		r := c.handle.R().
 			SetPathParam("id", id).
 			SetQueryParams(queryParams).
-			SetResult(&result).
-			SetError(&result).
-			Get(fmt.Sprintf("http://localhost:%d/folders/{id}/notes", c.port))
-		if err != nil {
-			return notes, err
-		}
+			SetResult(&result)
 
-		if resp.IsError() {
-			// handle response.
-			err = fmt.Errorf("got error response, raw dump:\n%s", resp.Dump())
+		_, err := c.send(r, http.MethodGet, "/folders/{id}/notes")
+		if err != nil {
+			if isNotFound(err) {
+				return notes, &NotFoundError{Type: "notebook", ID: id}
+			}
 
 			return notes, err
 		}
 
-		if resp.IsSuccess() {
-			for _, note := range result.Items {
-				notes = append(notes, note)
-			}
-
-			if result.HasMore {
-				page++
+		notes = append(notes, result.Items...)
 
-				queryParams["page"] = strconv.Itoa(page)
-
-				continue
-			} else {
-				return notes, nil
-			}
+		if !result.HasMore {
+			return notes, nil
 		}
 
-		// Handle response.
-		err = fmt.Errorf("got unexpected response, raw dump:\n%s", resp.Dump())
+		page++
 
-		return notes, err
+		queryParams["page"] = strconv.Itoa(page)
 	}
 }
 
@@ -1008,7 +960,6 @@ func (c *Client) GetAllNotebooks(fields string, orderBy string, orderDir string)
 	page := 1
 
 	queryParams := map[string]string{
-		"token":  c.apiToken,
 		"fields": fields,
 		"page":   strconv.Itoa(page),
 	}
@@ -1022,76 +973,40 @@ func (c *Client) GetAllNotebooks(fields string, orderBy string, orderDir string)
 	}
 
 	for {
-		resp, err := c.handle.R().
+		r := c.handle.R().
 			SetQueryParams(queryParams).
-			SetResult(&result).
-			SetError(&result).
-			Get(fmt.Sprintf("http://localhost:%d/folders", c.port))
-		if err != nil {
-			return notebooks, err
-		}
-
-		if resp.IsError() {
-			// Handle response.
-			err = fmt.Errorf("got error response, raw dump:\n%s", resp.Dump())
+			SetResult(&result)
 
+		_, err := c.send(r, http.MethodGet, "/folders")
+		if err != nil {
 			return notebooks, err
 		}
 
-		if resp.IsSuccess() {
-			for _, notebook := range result.Items {
-				notebooks = append(notebooks, notebook)
-			}
-
-			if result.HasMore {
-				page++
-
-				queryParams["page"] = strconv.Itoa(page)
+		notebooks = append(notebooks, result.Items...)
 
-				continue
-			} else {
-				return notebooks, nil
-			}
+		if !result.HasMore {
+			return notebooks, nil
 		}
 
-		// Handle response.
-		err = fmt.Errorf("got unexpected response, raw dump:\n%s", resp.Dump())
+		page++
 
-		return notebooks, err
+		queryParams["page"] = strconv.Itoa(page)
 	}
 }
 
 func (c *Client) GetNotebook(id string, fields string) (Notebook, error) {
 	var notebook Notebook
 
-	resp, err := c.handle.R().
+	r := c.handle.R().
 		SetPathParam("id", id).
-		SetQueryParam("token", c.apiToken).
 		SetQueryParam("fields", fields).
-		SetResult(&notebook).
-		SetError(&notebook).
-		Get(fmt.Sprintf("http://localhost:%d/folders/{id}", c.port))
-	if err != nil {
-		return notebook, err
-	}
-
-	if resp.IsError() {
-		if resp.StatusCode == 404 {
-			err = fmt.Errorf("could not find notebook with ID '%s'", id)
-		} else {
-			err = fmt.Errorf("got error response, raw dump:\n%s", resp.Dump())
-		}
-
-		return notebook, err
-	}
+		SetResult(&notebook)
 
-	if resp.IsSuccess() {
-		return notebook, nil
+	_, err := c.send(r, http.MethodGet, "/folders/{id}")
+	if isNotFound(err) {
+		return notebook, &NotFoundError{Type: "notebook", ID: id}
 	}
 
-	// Handle response.
-	err = fmt.Errorf("got unexpected response, raw dump:\n%s", resp.Dump())
-
 	return notebook, err
 }
 
@@ -1102,7 +1017,6 @@ func (c *Client) GetAllTags(orderBy string, orderDir string) ([]Tag, error) {
 	page := 1
 
 	queryParams := map[string]string{
-		"token":  c.apiToken,
 		"fields": "id,parent_id,title",
 		"page":   strconv.Itoa(page),
 	}
@@ -1116,94 +1030,54 @@ func (c *Client) GetAllTags(orderBy string, orderDir string) ([]Tag, error) {
 	}
 
 	for {
-		resp, err := c.handle.R().
+		r := c.handle.R().
 			SetQueryParams(queryParams).
-			SetResult(&result).
-			SetError(&result).
-			Get(fmt.Sprintf("http://localhost:%d/tags/", c.port))
+			SetResult(&result)
+
+		_, err := c.send(r, http.MethodGet, "/tags")
 		if err != nil {
 			return tags, err
 		}
 
-		if resp.IsError() {
-			// Handle response.
-			err = fmt.Errorf("got error response, raw dump:\n%s", resp.Dump())
+		tags = append(tags, result.Items...)
 
-			return tags, err
+		if !result.HasMore {
+			return tags, nil
 		}
 
-		if resp.IsSuccess() {
-			for _, tag := range result.Items {
-				tags = append(tags, tag)
-			}
-
-			if result.HasMore {
-				page++
-
-				queryParams["page"] = strconv.Itoa(page)
+		page++
 
-				continue
-			} else {
-				return tags, nil
-			}
-		}
-
-		// Handle response.
-		err = fmt.Errorf("got unexpected response, raw dump:\n%s", resp.Dump())
-
-		return tags, err
+		queryParams["page"] = strconv.Itoa(page)
 	}
 }
 
 func (c *Client) DeleteTag(id string) error {
-	resp, err := c.handle.R().
-		SetPathParam("id", id).
-		SetQueryParam("token", c.apiToken).
-		Delete(fmt.Sprintf("http://localhost:%d/tags/{id}", c.port))
-	if err != nil {
-		return err
-	}
+	_, err := c.send(c.handle.R().SetPathParam("id", id), http.MethodDelete, "/tags/{id}")
 
-	if resp.IsError() {
-		// Handle response.
-		err = fmt.Errorf("got error response, raw dump:\n%s", resp.Dump())
+	return err
+}
 
-		return err
-	}
+// DeleteNote deletes the note with the given ID. If permanent is not set,
+// Joplin versions with a trash move the note there.
+func (c *Client) DeleteNote(id string, permanent bool) error {
+	r := c.handle.R().
+		SetPathParam("id", id)
 
-	if resp.IsSuccess() {
-		return nil
+	if permanent {
+		r.SetQueryParam("permanent", "1")
 	}
 
-	// Handle response.
-	err = fmt.Errorf("got unexpected response, raw dump:\n%s", resp.Dump())
+	_, err := c.send(r, http.MethodDelete, "/notes/{id}")
 
 	return err
 }
 
 func (c *Client) DeleteTagFromNote(tagID string, noteID string) error {
-	resp, err := c.handle.R().
+	r := c.handle.R().
 		SetPathParam("tagID", tagID).
-		SetPathParam("noteID", noteID).
-		SetQueryParam("token", c.apiToken).
-		Delete(fmt.Sprintf("http://localhost:%d/tags/{tagID}/notes/{noteID}", c.port))
-	if err != nil {
-		return err
-	}
+		SetPathParam("noteID", noteID)
 
-	if resp.IsError() {
-		// Handle response.
-		err = fmt.Errorf("got error response, raw dump:\n%s", resp.Dump())
-
-		return err
-	}
-
-	if resp.IsSuccess() {
-		return nil
-	}
-
-	// Handle response.
-	err = fmt.Errorf("got unexpected response, raw dump:\n%s", resp.Dump())
+	_, err := c.send(r, http.MethodDelete, "/tags/{tagID}/notes/{noteID}")
 
 	return err
 }
@@ -1215,7 +1089,6 @@ func (c *Client) Search(query string, queryType string, fields string) ([]Item,
 	page := 1
 
 	queryParams := map[string]string{
-		"token": c.apiToken,
 		"page":  strconv.Itoa(page),
 		"query": query,
 	}
@@ -1229,47 +1102,62 @@ func (c *Client) Search(query string, queryType string, fields string) ([]Item,
 	}
 
 	for {
-		resp, err := c.handle.R().
+		r := c.handle.R().
 			SetQueryParams(queryParams).
-			SetResult(&result).
-			SetError(&result).
-			Get(fmt.Sprintf("http://localhost:%d/search", c.port))
+			SetResult(&result)
+
+		_, err := c.send(r, http.MethodGet, "/search")
 		if err != nil {
 			return items, err
 		}
 
-		if resp.IsError() {
-			// Handle response.
-			err = fmt.Errorf("got error response, raw dump:\n%s", resp.Dump())
+		items = append(items, result.Items...)
 
-			return items, err
+		if !result.HasMore {
+			return items, nil
 		}
 
-		if resp.IsSuccess() {
-			for _, item := range result.Items {
-				items = append(items, item)
-			}
+		page++
 
-			if result.HasMore {
-				page++
+		queryParams["page"] = strconv.Itoa(page)
+	}
+}
 
-				queryParams["page"] = strconv.Itoa(page)
+func (c *Client) GetApiToken() string {
+	return c.apiToken
+}
 
-				continue
-			}
+// Host returns the host of the Joplin Web Clipper service.
+func (c *Client) Host() string {
+	return c.host
+}
 
-			return items, nil
-		}
+// Port returns the port of the Joplin Web Clipper service.
+func (c *Client) Port() int {
+	return c.port
+}
 
-		// Handle response.
-		err = fmt.Errorf("got unexpected response, raw dump:\n%s", resp.Dump())
+// ErrInvalidToken is returned by CheckToken if Joplin rejects the API token.
+var ErrInvalidToken = errors.New("the API token is not valid")
 
-		return items, err
+// CheckToken checks that Joplin accepts the API token.
+func (c *Client) CheckToken() error {
+	if len(c.apiToken) == 0 {
+		return ErrInvalidToken
 	}
-}
 
-func (c *Client) GetApiToken() string {
-	return c.apiToken
+	r := c.handle.R().
+		SetQueryParams(map[string]string{
+			"fields": "id",
+			"limit":  "1",
+		})
+
+	_, err := c.send(r, http.MethodGet, "/notes")
+	if isStatus(err, http.StatusForbidden) {
+		return ErrInvalidToken
+	}
+
+	return err
 }
 
 func (nf NoteFormat) String() string {
@@ -1283,183 +1171,242 @@ func (nf NoteFormat) String() string {
 	return "unknown"
 }
 
-func (c *Client) CreateNote(title string, format NoteFormat, body string, notebook string, tags []string) error {
-	if format == Undefined {
-		return fmt.Errorf("unknown note format")
+// ExpandBody returns the content of the given file if the body is prefixed
+// with a '@', the standard input if the body is '-', otherwise the body
+// itself.
+func ExpandBody(body string) (string, error) {
+	var err error
+
+	if body == "-" {
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", err
+		}
+
+		return string(content), nil
 	}
 
-	// We've to get the ID of the notebook first.
-	items, err := c.Search(notebook, "folder", "")
-	if err != nil {
-		return err
+	// Check if the body is stored in a file.
+	if !strings.HasPrefix(body, "@") {
+		return body, nil
 	}
 
-	if len(items) != 1 {
-		return fmt.Errorf("could not find notebook called '%s'", notebook)
+	filename := strings.TrimPrefix(body, "@")
+
+	filename, err = homedir.Expand(filename)
+	if err != nil {
+		return "", err
 	}
 
-	// Check if the body is stored in a file.
-	if strings.HasPrefix(body, "@") {
-		filename := strings.TrimPrefix(body, "@")
+	filename, err = envsubst.String(filename)
+	if err != nil {
+		return "", err
+	}
 
-		filename, err = homedir.Expand(filename)
-		if err != nil {
-			return err
-		}
+	fileInfo, err := os.Stat(filename)
+	if err != nil {
+		return "", fmt.Errorf("file '%s' does not exist", filename)
+	}
 
-		filename, err = envsubst.String(filename)
-		if err != nil {
-			return err
-		}
+	if fileInfo.IsDir() {
+		return "", fmt.Errorf("filename '%s' is a directory", filename)
+	}
 
-		if fileInfo, err := os.Stat(filename); err != nil {
-			return fmt.Errorf("file '%s' does not exist", filename)
-		} else {
-			if fileInfo.IsDir() {
-				return fmt.Errorf("filename '%s' is a directory", filename)
-			}
+	fileContent, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
 
-			fileContent, err := os.ReadFile(filename)
-			if err != nil {
-				return err
-			}
+	return string(fileContent), nil
+}
 
-			body = string(fileContent)
-		}
+// CreateNote creates a note in the notebook with the given ID, name or path,
+// attaches the tags and returns the new note. The notebook and the tags are
+// checked before the note is created. Missing tags and notebooks are created
+// if createMissing is set, otherwise no note is created.
+func (c *Client) CreateNote(title string, format NoteFormat, body string, notebook string, tags []string, createMissing bool) (Note, error) {
+	if format == Undefined {
+		return Note{}, fmt.Errorf("unknown note format")
+	}
 
+	body, err := ExpandBody(body)
+	if err != nil {
+		return Note{}, err
 	}
 
-	var data map[string]string
+	note := Note{
+		Title: title,
+	}
 
 	if format == Markdown {
-		data = map[string]string{
-			"title": title,
-			"body":  body,
-		}
+		note.Body = body
 	} else {
-		data = map[string]string{
-			"title":     title,
-			"body_html": body,
-		}
+		note.BodyHTML = body
 	}
 
-	queryParams := map[string]string{
-		"token": c.apiToken,
+	return c.CreateNoteIn(note, notebook, tags, createMissing)
+}
+
+// CreateNoteIn creates the note in the notebook with the given ID, name or
+// path and attaches the tags. See CreateNote for the handling of missing
+// tags and notebooks.
+func (c *Client) CreateNoteIn(note Note, notebook string, tags []string, createMissing bool) (Note, error) {
+	var titles []string
+
+	seen := make(map[string]bool)
+
+	for _, tag := range tags {
+		if !seen[strings.ToLower(tag)] {
+			seen[strings.ToLower(tag)] = true
+			titles = append(titles, tag)
+		}
 	}
 
-	resp, err := c.handle.R().
-		SetQueryParams(queryParams).
-		SetBody(data).
-		Post(fmt.Sprintf("http://localhost:%d/notes", c.port))
+	found, missing, err := c.FindTags(titles)
 	if err != nil {
-		return err
+		return Note{}, err
 	}
 
-	if resp.IsError() {
-		// Handle response.
-		err = fmt.Errorf("got error response:\n%s\n%s", resp.Status, resp.Dump())
+	if len(missing) != 0 && !createMissing {
+		return Note{}, fmt.Errorf("could not find tags called '%s'", strings.Join(missing, "', '"))
+	}
 
-		return err
+	parent, err := c.ResolveNotebook(notebook, createMissing)
+	if err != nil {
+		return Note{}, err
 	}
 
-	if resp.IsSuccess() {
-		// Ok, we've successfully generated the note, next step is adding the specified tags.
+	created, err := c.CreateTags(missing)
+	if err != nil {
+		return Note{}, err
+	}
 
-		var note Note
+	note.ParentID = parent.ID
 
-		err := json.Unmarshal(resp.Bytes(), &note)
-		if err != nil {
-			return err
-		}
+	return c.CreateNoteWithTags(note, append(found, created...))
+}
 
-		for _, tag := range tags {
-			items, err := c.Search(tag, "tag", "")
-			if err != nil {
-				return err
-			}
+// CreateNoteWithTags creates the note and attaches the tags. If a tag cannot
+// be attached, the note is deleted again, so that no half-tagged note is left
+// behind.
+func (c *Client) CreateNoteWithTags(note Note, tags []Tag) (Note, error) {
+	note, err := c.CreateNoteItem(note)
+	if err != nil {
+		return Note{}, err
+	}
 
-			if len(items) != 1 {
-				return fmt.Errorf("could not find tag called '%s'", tag)
+	for _, tag := range tags {
+		err = c.AddTagToNote(tag.ID, note)
+		if err != nil {
+			deleteErr := c.DeleteNote(note.ID, true)
+			if deleteErr != nil {
+				return Note{}, fmt.Errorf("could not add tag '%s' to the new note (%w), and could not delete the note '%s' again: %v", tag.Title, err, note.ID, deleteErr)
 			}
 
-			err = c.AddTagToNote(items[0].ID, note)
-			if err != nil {
-				return err
-			}
+			return Note{}, fmt.Errorf("could not add tag '%s' to the new note: %w", tag.Title, err)
 		}
-
-		return c.MoveNoteToNotebook(note, items[0].ID)
 	}
 
-	// Handle response.
-	return fmt.Errorf("got unexpected response, raw dump:\n%s", resp.Dump())
+	return note, nil
 }
 
 func (c *Client) MoveNoteToNotebook(note Note, notebook string) error {
-	queryParams := map[string]string{
-		"token": c.apiToken,
-	}
-
 	note.ParentID = notebook
 
-	resp, err := c.handle.R().
+	r := c.handle.R().
 		SetPathParam("id", note.ID).
-		SetQueryParams(queryParams).
-		SetBody(note).
-		Put(fmt.Sprintf("http://localhost:%d/notes/{id}", c.port))
-	if err != nil {
-		return err
-	}
-
-	if resp.IsError() {
-		// Handle response.
-		return fmt.Errorf("got error response:\n%s\n%s", resp.Status, resp.Dump())
-	}
+		SetBody(note)
 
-	if resp.IsSuccess() {
-		return nil
-	}
+	_, err := c.send(r, http.MethodPut, "/notes/{id}")
 
-	// Handle response.
-	return fmt.Errorf("got unexpected response, raw dump:\n%s", resp.Dump())
+	return err
 }
 
 func (c *Client) AddTagToNote(tagID string, note Note) error {
-	queryParams := map[string]string{
-		"token": c.apiToken,
+	r := c.handle.R().
+		SetPathParam("id", tagID).
+		SetBody(note)
+
+	_, err := c.send(r, http.MethodPost, "/tags/{id}/notes")
+
+	return err
+}
+
+func (c *Client) UpdateNote(id string, props map[string]interface{}) (Note, error) {
+	var note Note
+
+	r := c.handle.R().
+		SetPathParam("id", id).
+		SetBody(props).
+		SetResult(&note)
+
+	_, err := c.send(r, http.MethodPut, "/notes/{id}")
+	if isNotFound(err) {
+		return note, &NotFoundError{Type: "note", ID: id}
 	}
 
-	resp, err := c.handle.R().
-		SetPathParam("id", tagID).
-		SetQueryParams(queryParams).
-		SetBody(note).
-		Post(fmt.Sprintf("http://localhost:%d/tags/{id}/notes", c.port))
+	return note, err
+}
+
+// SetNoteTags changes the tags of a note to the given titles. Missing tags
+// are created.
+func (c *Client) SetNoteTags(note Note, titles []string) error {
+	tags, err := c.GetNoteTags(note.ID)
 	if err != nil {
 		return err
 	}
 
-	if resp.IsError() {
-		// Handle response.
-		return fmt.Errorf("got error response:\n%s\n%s", resp.Status, resp.Dump())
+	wanted := make(map[string]bool)
+	for _, title := range titles {
+		wanted[strings.ToLower(title)] = true
 	}
 
-	if resp.IsSuccess() {
-		return nil
+	present := make(map[string]bool)
+
+	for _, tag := range tags {
+		present[strings.ToLower(tag.Title)] = true
+
+		if !wanted[strings.ToLower(tag.Title)] {
+			err = c.DeleteTagFromNote(tag.ID, note.ID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, title := range titles {
+		if present[strings.ToLower(title)] {
+			continue
+		}
+
+		tag, err := c.FindOrCreateTag(title)
+		if err != nil {
+			return err
+		}
+
+		err = c.AddTagToNote(tag.ID, note)
+		if err != nil {
+			return err
+		}
+
+		present[strings.ToLower(title)] = true
 	}
 
-	// Handle response.
-	return fmt.Errorf("got unexpected response, raw dump:\n%s", resp.Dump())
+	return nil
 }
 
-func (c *Client) GetAllResources(orderBy string, orderDir string) ([]Resource, error) {
+func (c *Client) GetAllResources(fields string, orderBy string, orderDir string) ([]Resource, error) {
 	var result resourcesResult
 	var resources []Resource
 
 	page := 1
 
+	if len(fields) == 0 {
+		fields = "id,title"
+	}
+
 	queryParams := map[string]string{
-		"token":  c.apiToken,
-		"fields": "id,title",
+		"fields": fields,
 		"page":   strconv.Itoa(page),
 	}
 
@@ -1472,76 +1419,224 @@ func (c *Client) GetAllResources(orderBy string, orderDir string) ([]Resource, e
 	}
 
 	for {
-		resp, err := c.handle.R().
+		r := c.handle.R().
 			SetQueryParams(queryParams).
-			SetResult(&result).
-			SetError(&result).
-			Get(fmt.Sprintf("http://localhost:%d/resources/", c.port))
+			SetResult(&result)
+
+		_, err := c.send(r, http.MethodGet, "/resources")
 		if err != nil {
 			return resources, err
 		}
 
-		if resp.IsError() {
-			// Handle response.
-			err = fmt.Errorf("got error response, raw dump:\n%s", resp.Dump())
+		resources = append(resources, result.Items...)
 
-			return resources, err
+		if !result.HasMore {
+			return resources, nil
 		}
 
-		if resp.IsSuccess() {
-			for _, resource := range result.Items {
-				resources = append(resources, resource)
+		page++
+
+		queryParams["page"] = strconv.Itoa(page)
+	}
+}
+
+func (c *Client) GetResource(id string, fields string) (Resource, error) {
+	var resource Resource
+
+	r := c.handle.R().
+		SetPathParam("id", id).
+		SetQueryParam("fields", fields).
+		SetResult(&resource)
+
+	_, err := c.send(r, http.MethodGet, "/resources/{id}")
+	if isNotFound(err) {
+		return resource, &NotFoundError{Type: "resource", ID: id}
+	}
+
+	return resource, err
+}
+
+func (c *Client) GetNoteTags(id string) ([]Tag, error) {
+	var result tagsResult
+	var tags []Tag
+
+	page := 1
+
+	queryParams := map[string]string{
+		"fields": "id,parent_id,title,created_time,updated_time,user_created_time,user_updated_time",
+		"page":   strconv.Itoa(page),
+	}
+
+	for {
+		r := c.handle.R().
+			SetPathParam("id", id).
+			SetQueryParams(queryParams).
+			SetResult(&result)
+
+		_, err := c.send(r, http.MethodGet, "/notes/{id}/tags")
+		if err != nil {
+			if isNotFound(err) {
+				return tags, &NotFoundError{Type: "note", ID: id}
 			}
 
-			if result.HasMore {
-				page++
+			return tags, err
+		}
+
+		tags = append(tags, result.Items...)
+
+		if !result.HasMore {
+			return tags, nil
+		}
+
+		page++
 
-				queryParams["page"] = strconv.Itoa(page)
+		queryParams["page"] = strconv.Itoa(page)
+	}
+}
+
+func (c *Client) GetNoteResources(id string, fields string) ([]Resource, error) {
+	var result resourcesResult
+	var resources []Resource
+
+	page := 1
+
+	queryParams := map[string]string{
+		"fields": fields,
+		"page":   strconv.Itoa(page),
+	}
 
-				continue
-			} else {
-				return resources, nil
+	for {
+		r := c.handle.R().
+			SetPathParam("id", id).
+			SetQueryParams(queryParams).
+			SetResult(&result)
+
+		_, err := c.send(r, http.MethodGet, "/notes/{id}/resources")
+		if err != nil {
+			if isNotFound(err) {
+				return resources, &NotFoundError{Type: "note", ID: id}
 			}
+
+			return resources, err
 		}
 
-		// Handle response.
-		err = fmt.Errorf("got unexpected response, raw dump:\n%s", resp.Dump())
+		resources = append(resources, result.Items...)
+
+		if !result.HasMore {
+			return resources, nil
+		}
 
-		return resources, err
+		page++
+
+		queryParams["page"] = strconv.Itoa(page)
 	}
 }
 
-func (c *Client) GetResource(id string, fields string) (Resource, error) {
-	var resource Resource
+func (c *Client) GetResourceFile(id string) ([]byte, error) {
+	resp, err := c.send(c.handle.R().SetPathParam("id", id), http.MethodGet, "/resources/{id}/file")
+	if isNotFound(err) {
+		return nil, &NotFoundError{Type: "resource", ID: id}
+	}
 
-	resp, err := c.handle.R().
-		SetPathParam("id", id).
-		SetQueryParam("token", c.apiToken).
-		SetQueryParam("fields", fields).
-		SetResult(&resource).
-		SetError(&resource).
-		Get(fmt.Sprintf("http://localhost:%d/tags/{id}", c.port))
 	if err != nil {
-		return resource, err
+		return nil, err
 	}
 
-	if resp.IsError() {
-		if resp.StatusCode == 404 {
-			err = fmt.Errorf("could not find tag with IDs '%s", id)
+	return resp.Bytes(), nil
+}
 
-		} else {
-			err = fmt.Errorf("got error response, raw dump:\n%s", resp.Dump())
-		}
+// itemBody converts an item into a request body. An empty ID is dropped, so
+// that Joplin assigns a new one.
+func itemBody(item interface{}) (map[string]interface{}, error) {
+	var body map[string]interface{}
 
-		return resource, err
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &body)
+	if err != nil {
+		return nil, err
 	}
 
-	if resp.IsSuccess() {
-		return resource, nil
+	if id, ok := body["id"].(string); ok && len(id) == 0 {
+		delete(body, "id")
 	}
 
-	// Handle response.
-	err = fmt.Errorf("got unexpected response, raw dump:\n%s", resp.Dump())
+	return body, nil
+}
 
-	return resource, err
+func (c *Client) CreateNoteItem(note Note) (Note, error) {
+	var created Note
+
+	body, err := itemBody(note)
+	if err != nil {
+		return created, err
+	}
+
+	r := c.handle.R().
+		SetBody(body).
+		SetResult(&created)
+
+	_, err = c.send(r, http.MethodPost, "/notes")
+
+	return created, err
+}
+
+func (c *Client) CreateNotebook(notebook Notebook) (Notebook, error) {
+	var created Notebook
+
+	body, err := itemBody(notebook)
+	if err != nil {
+		return created, err
+	}
+
+	r := c.handle.R().
+		SetBody(body).
+		SetResult(&created)
+
+	_, err = c.send(r, http.MethodPost, "/folders")
+
+	return created, err
+}
+
+func (c *Client) CreateTag(tag Tag) (Tag, error) {
+	var created Tag
+
+	body, err := itemBody(tag)
+	if err != nil {
+		return created, err
+	}
+
+	r := c.handle.R().
+		SetBody(body).
+		SetResult(&created)
+
+	_, err = c.send(r, http.MethodPost, "/tags")
+
+	return created, err
+}
+
+func (c *Client) CreateResource(resource Resource, filename string, data []byte) (Resource, error) {
+	var created Resource
+
+	body, err := itemBody(resource)
+	if err != nil {
+		return created, err
+	}
+
+	props, err := json.Marshal(body)
+	if err != nil {
+		return created, err
+	}
+
+	r := c.handle.R().
+		SetFileBytes("data", filename, data).
+		SetFormData(map[string]string{"props": string(props)}).
+		SetResult(&created)
+
+	_, err = c.send(r, http.MethodPost, "/resources")
+
+	return created, err
 }
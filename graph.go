@@ -0,0 +1,387 @@
+package goplin
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Edge types of the note graph.
+const (
+	EdgeLink     = "link"
+	EdgeTag      = "tag"
+	EdgeNotebook = "notebook"
+)
+
+// Node types of the note graph.
+const (
+	NodeNote     = "note"
+	NodeNotebook = "notebook"
+)
+
+// GraphNode is a note or, for notebook membership edges, a notebook.
+type GraphNode struct {
+	ID       string   `json:"id"`
+	Type     string   `json:"type"`
+	Title    string   `json:"title"`
+	Notebook string   `json:"notebook,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// GraphEdge connects two nodes. Edges of shared tags carry the tags as label
+// and their number as weight.
+type GraphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+	Label  string `json:"label,omitempty"`
+	Weight int    `json:"weight"`
+}
+
+// Graph is the graph of notes and their relations.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// NoteGraph builds the graph of the selected notes. The edge types are
+// EdgeLink for links between notes, EdgeTag for notes sharing tags and
+// EdgeNotebook for the membership of notes in their notebook.
+func (c *Client) NoteGraph(sel Selection, edgeTypes []string) (*Graph, error) {
+	for _, edgeType := range edgeTypes {
+		switch edgeType {
+		case EdgeLink, EdgeTag, EdgeNotebook:
+		default:
+			return nil, fmt.Errorf("unknown edge type '%s'", edgeType)
+		}
+	}
+
+	notes, err := c.SelectNotes(sel, "id,parent_id,title,body")
+	if err != nil {
+		return nil, err
+	}
+
+	notebooks, err := c.GetAllNotebooks("id,parent_id,title", "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := c.GetAllTags("title", "ASC")
+	if err != nil {
+		return nil, err
+	}
+
+	paths := NotebookPaths(notebooks)
+	included := make(map[string]bool, len(notes))
+
+	for _, note := range notes {
+		included[note.ID] = true
+	}
+
+	noteTags := make(map[string][]string)
+
+	tagNotes, err := c.GetNotesByTags(TagIDs(tags), "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	for i, tag := range tags {
+		for _, note := range tagNotes[i] {
+			if included[note.ID] {
+				noteTags[note.ID] = append(noteTags[note.ID], tag.Title)
+			}
+		}
+	}
+
+	g := &Graph{}
+
+	for _, note := range notes {
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID:       note.ID,
+			Type:     NodeNote,
+			Title:    note.Title,
+			Notebook: paths[note.ParentID],
+			Tags:     noteTags[note.ID],
+		})
+	}
+
+	for _, edgeType := range edgeTypes {
+		switch edgeType {
+		case EdgeLink:
+			g.addLinkEdges(notes, included)
+		case EdgeTag:
+			g.addTagEdges(notes, noteTags)
+		case EdgeNotebook:
+			g.addNotebookEdges(notes, paths)
+		}
+	}
+
+	return g, nil
+}
+
+func (g *Graph) addLinkEdges(notes []Note, included map[string]bool) {
+	seen := make(map[string]int)
+
+	for _, note := range notes {
+		for _, link := range ParseLinks(note.ID, note.Body) {
+			if !included[link.TargetID] || link.TargetID == note.ID {
+				continue
+			}
+
+			key := note.ID + link.TargetID
+			if i, ok := seen[key]; ok {
+				g.Edges[i].Weight++
+
+				continue
+			}
+
+			seen[key] = len(g.Edges)
+			g.Edges = append(g.Edges, GraphEdge{
+				Source: note.ID,
+				Target: link.TargetID,
+				Type:   EdgeLink,
+				Weight: 1,
+			})
+		}
+	}
+}
+
+func (g *Graph) addTagEdges(notes []Note, noteTags map[string][]string) {
+	// Only the notes sharing a tag are paired, by going through the notes of
+	// every tag.
+	var titles []string
+
+	tagNotes := make(map[string][]int)
+
+	for i, note := range notes {
+		for _, tag := range noteTags[note.ID] {
+			if _, ok := tagNotes[tag]; !ok {
+				titles = append(titles, tag)
+			}
+
+			tagNotes[tag] = append(tagNotes[tag], i)
+		}
+	}
+
+	sort.Strings(titles)
+
+	var pairs [][2]int
+
+	shared := make(map[[2]int][]string)
+
+	for _, tag := range titles {
+		indexes := tagNotes[tag]
+
+		for a := range indexes {
+			for b := a + 1; b < len(indexes); b++ {
+				pair := [2]int{indexes[a], indexes[b]}
+
+				if _, ok := shared[pair]; !ok {
+					pairs = append(pairs, pair)
+				}
+
+				shared[pair] = append(shared[pair], tag)
+			}
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+
+		return pairs[i][1] < pairs[j][1]
+	})
+
+	for _, pair := range pairs {
+		g.Edges = append(g.Edges, GraphEdge{
+			Source: notes[pair[0]].ID,
+			Target: notes[pair[1]].ID,
+			Type:   EdgeTag,
+			Label:  strings.Join(shared[pair], ", "),
+			Weight: len(shared[pair]),
+		})
+	}
+}
+
+func (g *Graph) addNotebookEdges(notes []Note, paths map[string]string) {
+	added := make(map[string]bool)
+
+	for _, note := range notes {
+		if len(note.ParentID) == 0 {
+			continue
+		}
+
+		if !added[note.ParentID] {
+			added[note.ParentID] = true
+			g.Nodes = append(g.Nodes, GraphNode{
+				ID:    note.ParentID,
+				Type:  NodeNotebook,
+				Title: paths[note.ParentID],
+			})
+		}
+
+		g.Edges = append(g.Edges, GraphEdge{
+			Source: note.ID,
+			Target: note.ParentID,
+			Type:   EdgeNotebook,
+			Weight: 1,
+		})
+	}
+}
+
+// WriteJSON writes the graph as JSON object with the lists "nodes" and
+// "edges".
+func (g *Graph) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(g)
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("digraph joplin {\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, node := range g.Nodes {
+		attrs := []string{"label=" + dotQuote(node.Title), "type=" + dotQuote(node.Type)}
+
+		if node.Type == NodeNotebook {
+			attrs = append(attrs, "shape=folder")
+		}
+
+		if len(node.Notebook) != 0 {
+			attrs = append(attrs, "notebook="+dotQuote(node.Notebook))
+		}
+
+		if len(node.Tags) != 0 {
+			attrs = append(attrs, "tags="+dotQuote(strings.Join(node.Tags, ",")))
+		}
+
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(node.ID), strings.Join(attrs, ", "))
+	}
+
+	for _, edge := range g.Edges {
+		attrs := []string{"type=" + dotQuote(edge.Type), fmt.Sprintf("weight=%d", edge.Weight)}
+
+		if edge.Type != EdgeLink {
+			// Shared tags and notebook membership have no direction.
+			attrs = append(attrs, "dir=none", "style=dashed")
+		}
+
+		if len(edge.Label) != 0 {
+			attrs = append(attrs, "label="+dotQuote(edge.Label))
+		}
+
+		fmt.Fprintf(&b, "  %s -> %s [%s];\n", dotQuote(edge.Source), dotQuote(edge.Target), strings.Join(attrs, ", "))
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+// WriteGraphML writes the graph as GraphML, e.g. for Gephi.
+func (g *Graph) WriteGraphML(w io.Writer) error {
+	doc := graphMLDocument{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "label", For: "node", Name: "label", Type: "string"},
+			{ID: "type", For: "node", Name: "type", Type: "string"},
+			{ID: "notebook", For: "node", Name: "notebook", Type: "string"},
+			{ID: "tags", For: "node", Name: "tags", Type: "string"},
+			{ID: "edge_type", For: "edge", Name: "type", Type: "string"},
+			{ID: "edge_label", For: "edge", Name: "label", Type: "string"},
+			{ID: "weight", For: "edge", Name: "weight", Type: "double"},
+		},
+	}
+
+	doc.Graph.EdgeDefault = "directed"
+
+	for _, node := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: node.ID,
+			Data: []graphMLData{
+				{Key: "label", Value: node.Title},
+				{Key: "type", Value: node.Type},
+				{Key: "notebook", Value: node.Notebook},
+				{Key: "tags", Value: strings.Join(node.Tags, ",")},
+			},
+		})
+	}
+
+	for _, edge := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: edge.Source,
+			Target: edge.Target,
+			Data: []graphMLData{
+				{Key: "edge_type", Value: edge.Type},
+				{Key: "edge_label", Value: edge.Label},
+				{Key: "weight", Value: fmt.Sprint(edge.Weight)},
+			},
+		})
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	err = enc.Encode(doc)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+
+	return err
+}
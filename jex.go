@@ -0,0 +1,587 @@
+package goplin
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Model types as used by Joplin in its raw item files.
+const (
+	modelTypeNote     = 1
+	modelTypeFolder   = 2
+	modelTypeResource = 4
+	modelTypeTag      = 5
+	modelTypeNoteTag  = 6
+)
+
+const jexTimeLayout = "2006-01-02T15:04:05.000Z"
+
+var jexTimeFields = map[string]bool{
+	"created_time":      true,
+	"updated_time":      true,
+	"user_created_time": true,
+	"user_updated_time": true,
+}
+
+// These fields are only understood by the Data API when creating a note and
+// are never part of a raw item file.
+var jexSkipFields = map[string]bool{
+	"type_":          true,
+	"body_html":      true,
+	"base_url":       true,
+	"image_data_url": true,
+	"crop_rect":      true,
+}
+
+// JEXArchive holds the items of a JEX (Joplin Export) archive.
+type JEXArchive struct {
+	Notebooks    []Notebook
+	Notes        []Note
+	Tags         []Tag
+	NoteTags     []NoteTag
+	Resources    []Resource
+	ResourceData map[string][]byte
+}
+
+func jsonKey(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("json"), ",")[0]
+}
+
+func serializeItem(item interface{}, modelType int) string {
+	var title, body string
+	var hasTitle bool
+	var props []string
+
+	v := reflect.ValueOf(item)
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		key := jsonKey(t.Field(i))
+		if len(key) == 0 || jexSkipFields[key] {
+			continue
+		}
+
+		f := v.Field(i)
+
+		switch key {
+		case "title":
+			title = f.String()
+			hasTitle = true
+			continue
+		case "body":
+			body = f.String()
+			continue
+		}
+
+		var value string
+
+		switch f.Kind() {
+		case reflect.Int:
+			if jexTimeFields[key] {
+				if f.Int() != 0 {
+					value = time.UnixMilli(f.Int()).UTC().Format(jexTimeLayout)
+				}
+			} else {
+				value = strconv.FormatInt(f.Int(), 10)
+			}
+		case reflect.Float64:
+			value = strconv.FormatFloat(f.Float(), 'f', -1, 64)
+		case reflect.String:
+			value = strings.NewReplacer("\n", "\\n", "\r", "\\r").Replace(f.String())
+		}
+
+		props = append(props, fmt.Sprintf("%s: %s", key, value))
+	}
+
+	props = append(props, fmt.Sprintf("type_: %d", modelType))
+
+	var parts []string
+
+	if hasTitle {
+		parts = append(parts, title)
+	}
+
+	if len(body) != 0 {
+		parts = append(parts, body)
+	}
+
+	parts = append(parts, strings.Join(props, "\n"))
+
+	return strings.Join(parts, "\n\n")
+}
+
+func unserializeItem(content string) (map[string]string, error) {
+	props := make(map[string]string)
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	i := len(lines) - 1
+
+	// Properties are read from the bottom up until the first empty line.
+	for ; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if len(line) == 0 {
+			break
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid property line '%s'", line)
+		}
+
+		props[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	if _, ok := props["type_"]; !ok {
+		return nil, fmt.Errorf("missing property 'type_'")
+	}
+
+	if i > 0 {
+		props["title"] = lines[0]
+
+		if i > 2 {
+			props["body"] = strings.Join(lines[2:i], "\n")
+		}
+	}
+
+	return props, nil
+}
+
+func decodeItem(props map[string]string, item interface{}) error {
+	v := reflect.ValueOf(item).Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		key := jsonKey(t.Field(i))
+
+		value, ok := props[key]
+		if !ok || len(key) == 0 {
+			continue
+		}
+
+		f := v.Field(i)
+
+		switch f.Kind() {
+		case reflect.Int:
+			if len(value) == 0 {
+				continue
+			}
+
+			if jexTimeFields[key] {
+				ts, err := time.Parse(jexTimeLayout, value)
+				if err != nil {
+					return fmt.Errorf("invalid time for '%s': %w", key, err)
+				}
+
+				f.SetInt(ts.UnixMilli())
+			} else {
+				n, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid number for '%s': %w", key, err)
+				}
+
+				f.SetInt(n)
+			}
+		case reflect.Float64:
+			if len(value) == 0 {
+				continue
+			}
+
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid number for '%s': %w", key, err)
+			}
+
+			f.SetFloat(n)
+		case reflect.String:
+			if key == "title" || key == "body" {
+				f.SetString(value)
+			} else {
+				f.SetString(strings.NewReplacer("\\n", "\n", "\\r", "\r").Replace(value))
+			}
+		}
+	}
+
+	return nil
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = tw.Write(data)
+
+	return err
+}
+
+func resourceFilename(resource Resource) string {
+	if len(resource.FileExtension) == 0 {
+		return resource.ID
+	}
+
+	return resource.ID + "." + resource.FileExtension
+}
+
+// Write stores the archive in the JEX format, which can be imported by the
+// Joplin desktop application.
+func (a *JEXArchive) Write(w io.Writer) error {
+	tw := tar.NewWriter(w)
+
+	var items []struct {
+		id      string
+		content string
+	}
+
+	add := func(id string, item interface{}, modelType int) {
+		items = append(items, struct {
+			id      string
+			content string
+		}{id, serializeItem(item, modelType)})
+	}
+
+	for _, notebook := range a.Notebooks {
+		add(notebook.ID, notebook, modelTypeFolder)
+	}
+
+	for _, note := range a.Notes {
+		add(note.ID, note, modelTypeNote)
+	}
+
+	for _, tag := range a.Tags {
+		add(tag.ID, tag, modelTypeTag)
+	}
+
+	for _, noteTag := range a.NoteTags {
+		add(noteTag.ID, noteTag, modelTypeNoteTag)
+	}
+
+	for _, resource := range a.Resources {
+		add(resource.ID, resource, modelTypeResource)
+	}
+
+	for _, item := range items {
+		err := writeTarFile(tw, item.id+".md", []byte(item.content))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, resource := range a.Resources {
+		data, ok := a.ResourceData[resource.ID]
+		if !ok {
+			return fmt.Errorf("missing data of resource '%s'", resource.ID)
+		}
+
+		err := writeTarFile(tw, path.Join("resources", resourceFilename(resource)), data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tw.Close()
+}
+
+// ReadJEX reads an archive in the JEX format.
+func ReadJEX(r io.Reader) (*JEXArchive, error) {
+	a := JEXArchive{
+		ResourceData: make(map[string][]byte),
+	}
+
+	tr := tar.NewReader(r)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		var buf bytes.Buffer
+
+		_, err = io.Copy(&buf, tr)
+		if err != nil {
+			return nil, err
+		}
+
+		name := path.Clean(header.Name)
+
+		if path.Dir(name) == "resources" {
+			id := strings.TrimSuffix(path.Base(name), path.Ext(name))
+			a.ResourceData[id] = buf.Bytes()
+
+			continue
+		}
+
+		if path.Ext(name) != ".md" {
+			continue
+		}
+
+		props, err := unserializeItem(buf.String())
+		if err != nil {
+			return nil, fmt.Errorf("could not read '%s': %w", name, err)
+		}
+
+		switch props["type_"] {
+		case strconv.Itoa(modelTypeFolder):
+			var notebook Notebook
+			err = decodeItem(props, &notebook)
+			a.Notebooks = append(a.Notebooks, notebook)
+		case strconv.Itoa(modelTypeNote):
+			var note Note
+			err = decodeItem(props, &note)
+			a.Notes = append(a.Notes, note)
+		case strconv.Itoa(modelTypeTag):
+			var tag Tag
+			err = decodeItem(props, &tag)
+			a.Tags = append(a.Tags, tag)
+		case strconv.Itoa(modelTypeNoteTag):
+			var noteTag NoteTag
+			err = decodeItem(props, &noteTag)
+			a.NoteTags = append(a.NoteTags, noteTag)
+		case strconv.Itoa(modelTypeResource):
+			var resource Resource
+			err = decodeItem(props, &resource)
+			a.Resources = append(a.Resources, resource)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("could not read '%s': %w", name, err)
+		}
+	}
+
+	return &a, nil
+}
+
+// CollectJEX builds an archive from the given notes together with their
+// notebooks, tags and resources. The notes should have been fetched with
+// AllNoteFields.
+func (c *Client) CollectJEX(notes []Note) (*JEXArchive, error) {
+	a := JEXArchive{
+		Notes:        notes,
+		ResourceData: make(map[string][]byte),
+	}
+
+	notebooks, err := c.GetAllNotebooks(AllNotebookFields, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	notebooksByID := make(map[string]Notebook)
+	for _, notebook := range notebooks {
+		notebooksByID[notebook.ID] = notebook
+	}
+
+	seen := make(map[string]bool)
+
+	for _, note := range notes {
+		// The whole path of notebooks is needed to rebuild the tree on import.
+		for id := note.ParentID; len(id) != 0 && !seen[id]; {
+			notebook, ok := notebooksByID[id]
+			if !ok {
+				break
+			}
+
+			seen[id] = true
+			a.Notebooks = append(a.Notebooks, notebook)
+			id = notebook.ParentID
+		}
+
+		tags, err := c.GetNoteTags(note.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tag := range tags {
+			if !seen[tag.ID] {
+				seen[tag.ID] = true
+				a.Tags = append(a.Tags, tag)
+			}
+
+			a.NoteTags = append(a.NoteTags, NoteTag{
+				ID:          NewItemID(),
+				NoteID:      note.ID,
+				TagID:       tag.ID,
+				CreatedTime: note.CreatedTime,
+				UpdatedTime: note.UpdatedTime,
+			})
+		}
+
+		resources, err := c.GetNoteResources(note.ID, AllResourceFields)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, resource := range resources {
+			if seen[resource.ID] {
+				continue
+			}
+
+			data, err := c.GetResourceFile(resource.ID)
+			if err != nil {
+				return nil, err
+			}
+
+			seen[resource.ID] = true
+			a.Resources = append(a.Resources, resource)
+			a.ResourceData[resource.ID] = data
+		}
+	}
+
+	return &a, nil
+}
+
+// ImportJEX creates the items of the archive in Joplin. All items get new IDs
+// and links between them are rewritten accordingly. Notebooks without a
+// parent in the archive are created below the notebook with the given ID,
+// which may be empty to create them at the top level.
+func (c *Client) ImportJEX(a *JEXArchive, notebookID string) error {
+	ids := make(map[string]string)
+
+	for _, notebook := range a.Notebooks {
+		ids[notebook.ID] = NewItemID()
+	}
+
+	for _, note := range a.Notes {
+		ids[note.ID] = NewItemID()
+	}
+
+	for _, resource := range a.Resources {
+		ids[resource.ID] = NewItemID()
+	}
+
+	parentID := func(id string) string {
+		if newID, ok := ids[id]; ok {
+			return newID
+		}
+
+		return notebookID
+	}
+
+	// Parents have to be created before their children.
+	created := make(map[string]bool)
+
+	for len(created) < len(a.Notebooks) {
+		progress := false
+
+		for _, notebook := range a.Notebooks {
+			if created[notebook.ID] {
+				continue
+			}
+
+			if _, ok := ids[notebook.ParentID]; ok && !created[notebook.ParentID] {
+				continue
+			}
+
+			oldID := notebook.ID
+			notebook.ID = ids[oldID]
+			notebook.ParentID = parentID(notebook.ParentID)
+
+			_, err := c.CreateNotebook(notebook)
+			if err != nil {
+				return err
+			}
+
+			created[oldID] = true
+			progress = true
+		}
+
+		if !progress {
+			return fmt.Errorf("notebooks in archive contain a cycle")
+		}
+	}
+
+	for _, tag := range a.Tags {
+		newTag, err := c.FindOrCreateTag(tag.Title)
+		if err != nil {
+			return err
+		}
+
+		ids[tag.ID] = newTag.ID
+	}
+
+	for _, resource := range a.Resources {
+		data, ok := a.ResourceData[resource.ID]
+		if !ok {
+			return fmt.Errorf("missing data of resource '%s'", resource.ID)
+		}
+
+		filename := resource.Filename
+		if len(filename) == 0 {
+			filename = resourceFilename(resource)
+		}
+
+		_, err := c.CreateResource(Resource{
+			ID:    ids[resource.ID],
+			Title: resource.Title,
+		}, filename, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, note := range a.Notes {
+		if _, ok := ids[note.ParentID]; !ok && len(notebookID) == 0 {
+			return fmt.Errorf("notebook of note '%s' is not part of the archive", note.Title)
+		}
+
+		note.ID = ids[note.ID]
+		note.ParentID = parentID(note.ParentID)
+		note.Body = RewriteNoteLinks(note.Body, ids)
+
+		_, err := c.CreateNoteItem(note)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, noteTag := range a.NoteTags {
+		noteID, ok := ids[noteTag.NoteID]
+		if !ok {
+			continue
+		}
+
+		tagID, ok := ids[noteTag.TagID]
+		if !ok {
+			continue
+		}
+
+		err := c.AddTagToNote(tagID, Note{ID: noteID})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RewriteNoteLinks replaces the item IDs in all ':/id' links of the body
+// according to the given mapping. Unknown IDs are kept.
+func RewriteNoteLinks(body string, ids map[string]string) string {
+	return itemLinkRegexp.ReplaceAllStringFunc(body, func(link string) string {
+		if newID, ok := ids[strings.TrimPrefix(link, ":/")]; ok {
+			return ":/" + newID
+		}
+
+		return link
+	})
+}
@@ -0,0 +1,190 @@
+package goplin
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestSerializeItemRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		item      interface{}
+		modelType int
+	}{
+		{
+			name: "note",
+			item: Note{
+				ID:              "0123456789abcdef0123456789abcdef",
+				ParentID:        "fedcba9876543210fedcba9876543210",
+				Title:           "Shopping",
+				Body:            "# Shopping\n\n- milk\n- bread",
+				CreatedTime:     1660000000123,
+				UpdatedTime:     1660000001456,
+				UserCreatedTime: 1660000000123,
+				UserUpdatedTime: 1660000001456,
+				IsTodo:          1,
+				Latitude:        52.5,
+				Longitude:       13.25,
+				Author:          "someone",
+				SourceURL:       "https://example.com/?a=1",
+				MarkupLanguage:  1,
+				Type:            modelTypeNote,
+			},
+			modelType: modelTypeNote,
+		},
+		{
+			name: "note without body",
+			item: Note{
+				ID:       "0123456789abcdef0123456789abcdef",
+				ParentID: "fedcba9876543210fedcba9876543210",
+				Title:    "Empty",
+				Type:     modelTypeNote,
+			},
+			modelType: modelTypeNote,
+		},
+		{
+			name: "note with blank lines in the body",
+			item: Note{
+				ID:    "0123456789abcdef0123456789abcdef",
+				Title: "Blank lines",
+				Body:  "first\n\n\nsecond\n\nthird",
+				Type:  modelTypeNote,
+			},
+			modelType: modelTypeNote,
+		},
+		{
+			name: "note with newline in a property",
+			item: Note{
+				ID:              "0123456789abcdef0123456789abcdef",
+				Title:           "Properties",
+				Body:            "text",
+				ApplicationData: "line 1\nline 2\r\nline 3",
+				Type:            modelTypeNote,
+			},
+			modelType: modelTypeNote,
+		},
+		{
+			name: "notebook",
+			item: Notebook{
+				ID:          "0123456789abcdef0123456789abcdef",
+				ParentID:    "fedcba9876543210fedcba9876543210",
+				Title:       "Projects",
+				CreatedTime: 1660000000000,
+				Icon:        `{"emoji":"📁"}`,
+			},
+			modelType: modelTypeFolder,
+		},
+		{
+			name: "tag",
+			item: Tag{
+				ID:          "0123456789abcdef0123456789abcdef",
+				Title:       "urgent",
+				UpdatedTime: 1660000000999,
+				Type:        modelTypeTag,
+			},
+			modelType: modelTypeTag,
+		},
+		{
+			name: "note tag",
+			item: NoteTag{
+				ID:     "0123456789abcdef0123456789abcdef",
+				NoteID: "11111111111111111111111111111111",
+				TagID:  "22222222222222222222222222222222",
+			},
+			modelType: modelTypeNoteTag,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := serializeItem(tt.item, tt.modelType)
+
+			props, err := unserializeItem(content)
+			if err != nil {
+				t.Fatalf("unserializeItem: %v\n%s", err, content)
+			}
+
+			if got, want := props["type_"], strconv.Itoa(tt.modelType); got != want {
+				t.Errorf("type_ = %q, want %q", got, want)
+			}
+
+			decoded := reflect.New(reflect.TypeOf(tt.item))
+
+			err = decodeItem(props, decoded.Interface())
+			if err != nil {
+				t.Fatalf("decodeItem: %v", err)
+			}
+
+			if got := decoded.Elem().Interface(); !reflect.DeepEqual(got, tt.item) {
+				t.Errorf("round trip mismatch\n got: %+v\nwant: %+v\ncontent:\n%s", got, tt.item, content)
+			}
+		})
+	}
+}
+
+func TestUnserializeItem(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "title, body and properties",
+			content: "Title\n\nBody\n\nid: abc\ntype_: 1",
+			want:    map[string]string{"title": "Title", "body": "Body", "id": "abc", "type_": "1"},
+		},
+		{
+			name:    "title only",
+			content: "Title\n\nid: abc\ntype_: 5",
+			want:    map[string]string{"title": "Title", "id": "abc", "type_": "5"},
+		},
+		{
+			name:    "properties only",
+			content: "id: abc\nnote_id: def\ntype_: 6",
+			want:    map[string]string{"id": "abc", "note_id": "def", "type_": "6"},
+		},
+		{
+			name:    "CRLF line endings",
+			content: "Title\r\n\r\nBody\r\n\r\nid: abc\r\ntype_: 1",
+			want:    map[string]string{"title": "Title", "body": "Body", "id": "abc", "type_": "1"},
+		},
+		{
+			name:    "value with colon",
+			content: "Title\n\nsource_url: https://example.com\ntype_: 1",
+			want:    map[string]string{"title": "Title", "source_url": "https://example.com", "type_": "1"},
+		},
+		{
+			name:    "missing type",
+			content: "Title\n\nid: abc",
+			wantErr: true,
+		},
+		{
+			name:    "invalid property line",
+			content: "Title\n\nid abc\ntype_: 1",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := unserializeItem(tt.content)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("unserializeItem() = %v, want error", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unserializeItem: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unserializeItem() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
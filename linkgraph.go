@@ -0,0 +1,287 @@
+package goplin
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// itemLinkPattern matches the ':/id' target of all links to Joplin items.
+const itemLinkPattern = `:/([0-9a-f]{32})`
+
+var (
+	itemLinkRegexp          = regexp.MustCompile(itemLinkPattern)
+	markdownItemLinkRegexp  = regexp.MustCompile(`(!?)\[([^\]]*)\]\(\s*` + itemLinkPattern + `(#[^)\s]*)?(?:\s+"[^"]*")?\s*\)`)
+	referenceItemLinkRegexp = regexp.MustCompile(`^\s{0,3}\[([^\]]+)\]:\s*` + itemLinkPattern + `(#\S*)?`)
+	htmlItemLinkRegexp      = regexp.MustCompile(`(href|src)=["']` + itemLinkPattern + `(#[^"']*)?["']`)
+)
+
+// Link is a link from a note to another Joplin item, written as
+// '[text](:/id)' in Markdown or as 'href=":/id"' in HTML.
+type Link struct {
+	SourceID string
+	TargetID string
+	Anchor   string
+	Text     string
+	Line     int
+	Image    bool
+}
+
+// ParseLinks returns the links to Joplin items in the body of a note. Links
+// in code blocks are ignored.
+func ParseLinks(sourceID string, body string) []Link {
+	var links []Link
+
+	inCode := false
+
+	for i, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+
+			continue
+		}
+
+		if inCode {
+			continue
+		}
+
+		for _, m := range markdownItemLinkRegexp.FindAllStringSubmatch(line, -1) {
+			links = append(links, Link{
+				SourceID: sourceID,
+				TargetID: m[3],
+				Anchor:   strings.TrimPrefix(m[4], "#"),
+				Text:     m[2],
+				Line:     i + 1,
+				Image:    m[1] == "!",
+			})
+		}
+
+		if m := referenceItemLinkRegexp.FindStringSubmatch(line); m != nil {
+			links = append(links, Link{
+				SourceID: sourceID,
+				TargetID: m[2],
+				Anchor:   strings.TrimPrefix(m[3], "#"),
+				Text:     m[1],
+				Line:     i + 1,
+			})
+		}
+
+		for _, m := range htmlItemLinkRegexp.FindAllStringSubmatch(line, -1) {
+			links = append(links, Link{
+				SourceID: sourceID,
+				TargetID: m[2],
+				Anchor:   strings.TrimPrefix(m[3], "#"),
+				Line:     i + 1,
+				Image:    m[1] == "src",
+			})
+		}
+	}
+
+	return links
+}
+
+// LinkGraph holds the links between all notes.
+type LinkGraph struct {
+	Notes     map[string]Note
+	Notebooks map[string]Notebook
+	Resources map[string]Resource
+	Outgoing  map[string][]Link
+	Incoming  map[string][]Link
+}
+
+// NewLinkGraph builds the link graph of the given notes. Links to notebooks
+// and resources are valid targets, but are not part of the graph.
+func NewLinkGraph(notes []Note, notebooks []Notebook, resources []Resource) *LinkGraph {
+	g := &LinkGraph{
+		Notes:     make(map[string]Note, len(notes)),
+		Notebooks: make(map[string]Notebook, len(notebooks)),
+		Resources: make(map[string]Resource, len(resources)),
+		Outgoing:  make(map[string][]Link),
+		Incoming:  make(map[string][]Link),
+	}
+
+	for _, note := range notes {
+		g.Notes[note.ID] = note
+	}
+
+	for _, notebook := range notebooks {
+		g.Notebooks[notebook.ID] = notebook
+	}
+
+	for _, resource := range resources {
+		g.Resources[resource.ID] = resource
+	}
+
+	for _, note := range notes {
+		for _, link := range ParseLinks(note.ID, note.Body) {
+			g.Outgoing[note.ID] = append(g.Outgoing[note.ID], link)
+
+			if _, ok := g.Notes[link.TargetID]; ok && link.TargetID != note.ID {
+				g.Incoming[link.TargetID] = append(g.Incoming[link.TargetID], link)
+			}
+		}
+	}
+
+	return g
+}
+
+// LinkGraph fetches all notes, notebooks and resources and builds the link
+// graph.
+func (c *Client) LinkGraph() (*LinkGraph, error) {
+	notes, err := c.GetAllNotes("id,parent_id,title,body", "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	notebooks, err := c.GetAllNotebooks("id,parent_id,title", "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := c.GetAllResources("id,title", "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLinkGraph(notes, notebooks, resources), nil
+}
+
+// LinkTargets returns the titles of the notes, notebooks and resources the
+// links point to, without fetching all notes like LinkGraph. Targets which do
+// not exist are missing from the map.
+func (c *Client) LinkTargets(links []Link) (map[string]string, error) {
+	var ids []string
+
+	seen := make(map[string]bool)
+
+	for _, link := range links {
+		if !seen[link.TargetID] {
+			seen[link.TargetID] = true
+			ids = append(ids, link.TargetID)
+		}
+	}
+
+	titles := make([]string, len(ids))
+	found := make([]bool, len(ids))
+
+	err := ForEachID(ids, c.bulkWorkers, func(i int, id string) error {
+		note, err := c.GetNote(id, "id,title")
+		if err == nil {
+			titles[i], found[i] = note.Title, true
+
+			return nil
+		}
+
+		if !isNotFound(err) {
+			return err
+		}
+
+		notebook, err := c.GetNotebook(id, "id,title")
+		if err == nil {
+			titles[i], found[i] = notebook.Title, true
+
+			return nil
+		}
+
+		if !isNotFound(err) {
+			return err
+		}
+
+		resource, err := c.GetResource(id, "id,title")
+		if err == nil {
+			titles[i], found[i] = resource.Title, true
+
+			return nil
+		}
+
+		if isNotFound(err) {
+			return nil
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make(map[string]string, len(ids))
+
+	for i, id := range ids {
+		if found[i] {
+			targets[id] = titles[i]
+		}
+	}
+
+	return targets, nil
+}
+
+// Exists reports whether the ID belongs to a note, notebook or resource.
+func (g *LinkGraph) Exists(id string) bool {
+	if _, ok := g.Notes[id]; ok {
+		return true
+	}
+
+	if _, ok := g.Notebooks[id]; ok {
+		return true
+	}
+
+	_, ok := g.Resources[id]
+
+	return ok
+}
+
+// Links returns the outgoing links of the note.
+func (g *LinkGraph) Links(id string) []Link {
+	return g.Outgoing[id]
+}
+
+// Backlinks returns the links from other notes to the note.
+func (g *LinkGraph) Backlinks(id string) []Link {
+	return g.Incoming[id]
+}
+
+// BrokenLinks returns the links whose target does not exist, ordered by the
+// title of the linking note.
+func (g *LinkGraph) BrokenLinks() []Link {
+	var broken []Link
+
+	for _, links := range g.Outgoing {
+		for _, link := range links {
+			if !g.Exists(link.TargetID) {
+				broken = append(broken, link)
+			}
+		}
+	}
+
+	sort.SliceStable(broken, func(i, j int) bool {
+		a, b := g.Notes[broken[i].SourceID], g.Notes[broken[j].SourceID]
+		if a.Title != b.Title {
+			return a.Title < b.Title
+		}
+
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+
+		return broken[i].Line < broken[j].Line
+	})
+
+	return broken
+}
+
+// Title returns the title of the note, notebook or resource with the ID.
+func (g *LinkGraph) Title(id string) string {
+	if note, ok := g.Notes[id]; ok {
+		return note.Title
+	}
+
+	if notebook, ok := g.Notebooks[id]; ok {
+		return notebook.Title
+	}
+
+	if resource, ok := g.Resources[id]; ok {
+		return resource.Title
+	}
+
+	return ""
+}
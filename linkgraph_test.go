@@ -0,0 +1,152 @@
+package goplin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLinks(t *testing.T) {
+	const (
+		a = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+		b = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	)
+
+	tests := []struct {
+		name string
+		body string
+		want []Link
+	}{
+		{
+			name: "no links",
+			body: "text [web](https://example.com) [short](:/abc)",
+		},
+		{
+			name: "inline links",
+			body: "see [A](:/" + a + ") and [B](:/" + b + "#setup)\n\n[spaces]( :/" + a + ` "title" )`,
+			want: []Link{
+				{SourceID: "s", TargetID: a, Text: "A", Line: 1},
+				{SourceID: "s", TargetID: b, Anchor: "setup", Text: "B", Line: 1},
+				{SourceID: "s", TargetID: a, Text: "spaces", Line: 3},
+			},
+		},
+		{
+			name: "image",
+			body: "![diagram](:/" + a + ")",
+			want: []Link{
+				{SourceID: "s", TargetID: a, Text: "diagram", Line: 1, Image: true},
+			},
+		},
+		{
+			name: "reference-style link",
+			body: "see [the plan][1]\n\n[1]: :/" + a + "#goals\n    [2]: :/" + b,
+			want: []Link{
+				{SourceID: "s", TargetID: a, Anchor: "goals", Text: "1", Line: 3},
+			},
+		},
+		{
+			name: "HTML links",
+			body: `<a href=":/` + a + `#top">A</a> <img src=':/` + b + `'/>`,
+			want: []Link{
+				{SourceID: "s", TargetID: a, Anchor: "top", Line: 1},
+				{SourceID: "s", TargetID: b, Line: 1, Image: true},
+			},
+		},
+		{
+			name: "links in code blocks are ignored",
+			body: "```\n[A](:/" + a + ")\n```\n  ```go\n[B](:/" + b + ")\n  ```\n[B](:/" + b + ")",
+			want: []Link{
+				{SourceID: "s", TargetID: b, Text: "B", Line: 7},
+			},
+		},
+		{
+			name: "unclosed code block",
+			body: "[A](:/" + a + ")\n```\n[B](:/" + b + ")",
+			want: []Link{
+				{SourceID: "s", TargetID: a, Text: "A", Line: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseLinks("s", tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLinks(%q) = %+v, want %+v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkGraph(t *testing.T) {
+	const (
+		first     = "11111111111111111111111111111111"
+		second    = "22222222222222222222222222222222"
+		third     = "33333333333333333333333333333333"
+		notebook  = "44444444444444444444444444444444"
+		resource  = "55555555555555555555555555555555"
+		missing   = "66666666666666666666666666666666"
+		deleted   = "77777777777777777777777777777777"
+		unrelated = "88888888888888888888888888888888"
+	)
+
+	notes := []Note{
+		{ID: second, Title: "B", Body: "[gone](:/" + missing + ")\n[first](:/" + first + ")\n[self](:/" + second + ")"},
+		{ID: first, Title: "A", Body: "[second](:/" + second + ") [nb](:/" + notebook + ")\n![img](:/" + resource + ")\n[x](:/" + deleted + ") [y](:/" + missing + ")"},
+		{ID: third, Title: "C", Body: "```\n[gone](:/" + missing + ")\n```"},
+	}
+
+	g := NewLinkGraph(notes, []Notebook{{ID: notebook, Title: "Notebook"}}, []Resource{{ID: resource, Title: "image.png"}})
+
+	tests := []struct {
+		name string
+		got  []Link
+		want []Link
+	}{
+		{
+			name: "links",
+			got:  g.Links(second),
+			want: []Link{
+				{SourceID: second, TargetID: missing, Text: "gone", Line: 1},
+				{SourceID: second, TargetID: first, Text: "first", Line: 2},
+				{SourceID: second, TargetID: second, Text: "self", Line: 3},
+			},
+		},
+		{
+			name: "backlinks without links to itself",
+			got:  g.Backlinks(second),
+			want: []Link{
+				{SourceID: first, TargetID: second, Text: "second", Line: 1},
+			},
+		},
+		{
+			name: "no backlinks",
+			got:  g.Backlinks(third),
+		},
+		{
+			name: "broken links ordered by note title and line",
+			got:  g.BrokenLinks(),
+			want: []Link{
+				{SourceID: first, TargetID: deleted, Text: "x", Line: 3},
+				{SourceID: first, TargetID: missing, Text: "y", Line: 3},
+				{SourceID: second, TargetID: missing, Text: "gone", Line: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("got %+v, want %+v", tt.got, tt.want)
+			}
+		})
+	}
+
+	for id, want := range map[string]string{first: "A", notebook: "Notebook", resource: "image.png", unrelated: ""} {
+		if got := g.Title(id); got != want {
+			t.Errorf("Title(%s) = %q, want %q", id, got, want)
+		}
+
+		if got := g.Exists(id); got != (len(want) != 0) {
+			t.Errorf("Exists(%s) = %v, want %v", id, got, len(want) != 0)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package goplin
+
+import (
+	"regexp"
+	"strings"
+)
+
+// lcsMatches returns for every line of a the index of the matching line in b
+// according to a longest common subsequence, or -1.
+func lcsMatches(a []string, b []string) []int {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	matches := make([]int, len(a))
+
+	i, j := 0, 0
+
+	for i < len(a) && j < len(b) {
+		if a[i] == b[j] {
+			matches[i] = j
+			i++
+			j++
+		} else if lengths[i+1][j] >= lengths[i][j+1] {
+			matches[i] = -1
+			i++
+		} else {
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		matches[i] = -1
+	}
+
+	return matches
+}
+
+func equalLines(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+var conflictMarkerRegexp = regexp.MustCompile(`(?m)^(<{7}|>{7})( |$)`)
+
+// HasConflictMarkers reports whether the text still contains conflict
+// markers as written by Merge3.
+func HasConflictMarkers(text string) bool {
+	return conflictMarkerRegexp.MatchString(text)
+}
+
+// Merge3 merges the changes from base to local and from base to remote line
+// by line. Conflicting changes are kept with conflict markers, in which case
+// the second return value is true.
+func Merge3(base string, local string, remote string) (string, bool) {
+	baseLines := strings.SplitAfter(base, "\n")
+	localLines := strings.SplitAfter(local, "\n")
+	remoteLines := strings.SplitAfter(remote, "\n")
+
+	toLocal := lcsMatches(baseLines, localLines)
+	toRemote := lcsMatches(baseLines, remoteLines)
+
+	var out []string
+
+	conflicts := false
+
+	i, j, k := 0, 0, 0
+
+	for i < len(baseLines) || j < len(localLines) || k < len(remoteLines) {
+		// Find the next base line which is unchanged in both versions.
+		m := i
+
+		for m < len(baseLines) && (toLocal[m] < j || toRemote[m] < k) {
+			m++
+		}
+
+		endLocal, endRemote := len(localLines), len(remoteLines)
+
+		if m < len(baseLines) {
+			endLocal, endRemote = toLocal[m], toRemote[m]
+		}
+
+		if m == i && endLocal == j && endRemote == k {
+			out = append(out, baseLines[i])
+			i++
+			j++
+			k++
+
+			continue
+		}
+
+		b, l, r := baseLines[i:m], localLines[j:endLocal], remoteLines[k:endRemote]
+
+		switch {
+		case equalLines(l, b):
+			out = append(out, r...)
+		case equalLines(r, b), equalLines(l, r):
+			out = append(out, l...)
+		default:
+			conflicts = true
+
+			out = append(out, "<<<<<<< local\n")
+			out = append(out, terminated(l)...)
+			out = append(out, "=======\n")
+			out = append(out, terminated(r)...)
+			out = append(out, ">>>>>>> joplin\n")
+		}
+
+		i, j, k = m, endLocal, endRemote
+	}
+
+	return strings.Join(out, ""), conflicts
+}
+
+// terminated makes sure that the last line ends with a newline, so that
+// conflict markers always start on a line of their own.
+func terminated(lines []string) []string {
+	if len(lines) == 0 || strings.HasSuffix(lines[len(lines)-1], "\n") {
+		return lines
+	}
+
+	result := append([]string{}, lines...)
+	result[len(result)-1] += "\n"
+
+	return result
+}
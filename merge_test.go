@@ -0,0 +1,131 @@
+package goplin
+
+import (
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name          string
+		base          string
+		local         string
+		remote        string
+		want          string
+		wantConflicts bool
+	}{
+		{
+			name:   "no changes",
+			base:   "a\nb\nc\n",
+			local:  "a\nb\nc\n",
+			remote: "a\nb\nc\n",
+			want:   "a\nb\nc\n",
+		},
+		{
+			name:   "local change only",
+			base:   "a\nb\nc\n",
+			local:  "a\nB\nc\n",
+			remote: "a\nb\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:   "remote change only",
+			base:   "a\nb\nc\n",
+			local:  "a\nb\nc\n",
+			remote: "a\nb\nC\n",
+			want:   "a\nb\nC\n",
+		},
+		{
+			name:   "changes in different lines",
+			base:   "a\nb\nc\nd\n",
+			local:  "A\nb\nc\nd\n",
+			remote: "a\nb\nc\nD\n",
+			want:   "A\nb\nc\nD\n",
+		},
+		{
+			name:   "same change on both sides",
+			base:   "a\nb\nc\n",
+			local:  "a\nX\nc\n",
+			remote: "a\nX\nc\n",
+			want:   "a\nX\nc\n",
+		},
+		{
+			name:   "insertions on both sides",
+			base:   "a\nb\nc\n",
+			local:  "a\nlocal\nb\nc\n",
+			remote: "a\nb\nc\nremote\n",
+			want:   "a\nlocal\nb\nc\nremote\n",
+		},
+		{
+			name:   "local deletion",
+			base:   "a\nb\nc\nd\n",
+			local:  "a\nc\nd\n",
+			remote: "a\nb\nc\nD\n",
+			want:   "a\nc\nD\n",
+		},
+		{
+			name:          "conflicting change",
+			base:          "a\nb\nc\n",
+			local:         "a\nlocal\nc\n",
+			remote:        "a\nremote\nc\n",
+			want:          "a\n<<<<<<< local\nlocal\n=======\nremote\n>>>>>>> joplin\nc\n",
+			wantConflicts: true,
+		},
+		{
+			name:          "conflict in the last line without newline",
+			base:          "a\nb",
+			local:         "a\nlocal",
+			remote:        "a\nremote",
+			want:          "a\n<<<<<<< local\nlocal\n=======\nremote\n>>>>>>> joplin\n",
+			wantConflicts: true,
+		},
+		{
+			name:   "empty base",
+			base:   "",
+			local:  "",
+			remote: "new\n",
+			want:   "new\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := Merge3(tt.base, tt.local, tt.remote)
+
+			if got != tt.want {
+				t.Errorf("Merge3() = %q, want %q", got, tt.want)
+			}
+
+			if conflicts != tt.wantConflicts {
+				t.Errorf("Merge3() conflicts = %v, want %v", conflicts, tt.wantConflicts)
+			}
+
+			if HasConflictMarkers(got) != tt.wantConflicts {
+				t.Errorf("HasConflictMarkers(%q) = %v, want %v", got, !tt.wantConflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
+
+func TestHasConflictMarkers(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{name: "plain text", text: "a\nb\n", want: false},
+		{name: "start marker", text: "a\n<<<<<<< local\nb\n", want: true},
+		{name: "end marker", text: "a\n>>>>>>> joplin\n", want: true},
+		{name: "bare marker", text: "<<<<<<<\n", want: true},
+		{name: "marker inside a line", text: "a <<<<<<< b\n", want: false},
+		{name: "longer run", text: "<<<<<<<<\n", want: false},
+		{name: "quote", text: "> > > quoted\n", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasConflictMarkers(tt.text); got != tt.want {
+				t.Errorf("HasConflictMarkers(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,595 @@
+package goplin
+
+import (
+	"fmt"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	wikilinkRegexp     = regexp.MustCompile(`(!?)\[\[([^\]|#]*)(#[^\]|]*)?(?:\|([^\]]*))?\]\]`)
+	markdownLinkRegexp = regexp.MustCompile(`(!?)\[([^\]]*)\]\(([^)\s]+)\)`)
+	inlineTagRegexp    = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_/-]+)`)
+	codeFenceRegexp    = regexp.MustCompile("(?ms)^```.*?^```")
+	badFilenameChars   = strings.NewReplacer("/", "-", "\\", "-", ":", "-", "*", "-", "?", "-", "\"", "-", "<", "-", ">", "-", "|", "-")
+)
+
+// InlineTags returns the '#tags' used in a Markdown body. Code blocks and
+// purely numeric tags are ignored.
+func InlineTags(body string) []string {
+	var tags []string
+
+	seen := make(map[string]bool)
+	body = codeFenceRegexp.ReplaceAllString(body, "")
+
+	for _, m := range inlineTagRegexp.FindAllStringSubmatch(body, -1) {
+		tag := strings.TrimRight(m[1], "/")
+		if len(tag) == 0 || strings.Trim(tag, "0123456789") == "" {
+			continue
+		}
+
+		if !seen[strings.ToLower(tag)] {
+			seen[strings.ToLower(tag)] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+func isImageFile(name string) bool {
+	return strings.HasPrefix(mime.TypeByExtension(path.Ext(name)), "image/")
+}
+
+type obsidianVault struct {
+	dir   string
+	notes map[string]string
+	// attachments maps the lower case paths relative to the vault to the
+	// files, names maps the lower case base names to all these paths.
+	attachments map[string]string
+	names       map[string][]string
+	resources   map[string]string
+}
+
+func vaultKey(name string) string {
+	return strings.ToLower(strings.TrimSuffix(filepath.ToSlash(name), ".md"))
+}
+
+func (v *obsidianVault) noteID(target string) (string, bool) {
+	key := vaultKey(strings.TrimSpace(target))
+
+	if id, ok := v.notes[key]; ok {
+		return id, true
+	}
+
+	id, ok := v.notes[path.Base(key)]
+
+	return id, ok
+}
+
+// attachment returns the file of the vault a link from a note in noteDir
+// points to. Like in Obsidian the target is either relative to the note, to
+// the vault or a file name which is unique in the vault.
+func (v *obsidianVault) attachment(noteDir string, target string) (string, bool) {
+	target = strings.ToLower(filepath.ToSlash(strings.TrimSpace(target)))
+
+	if file, ok := v.attachments[path.Join(strings.ToLower(filepath.ToSlash(noteDir)), target)]; ok {
+		return file, true
+	}
+
+	if file, ok := v.attachments[path.Clean(target)]; ok {
+		return file, true
+	}
+
+	if files := v.names[path.Base(target)]; len(files) == 1 {
+		return files[0], true
+	}
+
+	return "", false
+}
+
+// headingAnchor returns the anchor Joplin uses for a heading.
+func headingAnchor(heading string) string {
+	var b strings.Builder
+
+	for _, r := range strings.ToLower(strings.TrimSpace(heading)) {
+		switch {
+		case unicode.IsSpace(r):
+			b.WriteRune('-')
+		case unicode.IsLetter(r), unicode.IsNumber(r), r == '-', r == '_':
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+func (c *Client) vaultResource(v *obsidianVault, noteDir string, target string) (string, error) {
+	file, ok := v.attachment(noteDir, target)
+	if !ok {
+		return "", nil
+	}
+
+	if id, ok := v.resources[file]; ok {
+		return id, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(v.dir, file))
+	if err != nil {
+		return "", err
+	}
+
+	name := filepath.Base(file)
+
+	resource, err := c.CreateResource(Resource{Title: name}, name, data)
+	if err != nil {
+		return "", err
+	}
+
+	v.resources[file] = resource.ID
+
+	return resource.ID, nil
+}
+
+func (c *Client) convertVaultLinks(v *obsidianVault, noteDir string, body string) (string, error) {
+	var retErr error
+
+	body = wikilinkRegexp.ReplaceAllStringFunc(body, func(link string) string {
+		m := wikilinkRegexp.FindStringSubmatch(link)
+		embed, target, heading, alias := m[1] == "!", m[2], strings.TrimPrefix(m[3], "#"), m[4]
+
+		text := alias
+		if len(text) == 0 {
+			text = strings.TrimSuffix(path.Base(strings.TrimSpace(target)), ".md")
+		}
+
+		anchor := ""
+		if len(heading) != 0 {
+			anchor = "#" + headingAnchor(heading)
+		}
+
+		if ext := path.Ext(target); len(ext) != 0 && ext != ".md" {
+			id, err := c.vaultResource(v, noteDir, target)
+			if err != nil {
+				retErr = err
+			}
+
+			if len(id) == 0 {
+				return link
+			}
+
+			if embed && isImageFile(target) {
+				return fmt.Sprintf("![%s](:/%s)", alias, id)
+			}
+
+			return fmt.Sprintf("[%s](:/%s)", text, id)
+		}
+
+		if len(strings.TrimSpace(target)) == 0 && len(anchor) != 0 {
+			if len(alias) == 0 {
+				text = heading
+			}
+
+			return fmt.Sprintf("[%s](%s)", text, anchor)
+		}
+
+		if id, ok := v.noteID(target); ok {
+			return fmt.Sprintf("[%s](:/%s%s)", text, id, anchor)
+		}
+
+		return link
+	})
+
+	body = markdownLinkRegexp.ReplaceAllStringFunc(body, func(link string) string {
+		m := markdownLinkRegexp.FindStringSubmatch(link)
+		prefix, text, target := m[1], m[2], m[3]
+
+		if strings.Contains(target, "://") || strings.HasPrefix(target, ":/") || strings.HasPrefix(target, "#") {
+			return link
+		}
+
+		target = strings.ReplaceAll(target, "%20", " ")
+
+		if path.Ext(target) == ".md" {
+			if id, ok := v.noteID(target); ok {
+				return fmt.Sprintf("[%s](:/%s)", text, id)
+			}
+
+			return link
+		}
+
+		id, err := c.vaultResource(v, noteDir, target)
+		if err != nil {
+			retErr = err
+		}
+
+		if len(id) == 0 {
+			return link
+		}
+
+		return fmt.Sprintf("%s[%s](:/%s)", prefix, text, id)
+	})
+
+	return body, retErr
+}
+
+// ImportObsidianVault creates a note for every Markdown file of the vault.
+// Folders become notebooks below the notebook with the given ID, wikilinks
+// become Joplin links, attachments become resources and '#tags' become tags.
+// If no notebook ID is given, a notebook named after the vault is created.
+func (c *Client) ImportObsidianVault(dir string, notebookID string) ([]Note, error) {
+	var files []string
+
+	v := obsidianVault{
+		dir:         dir,
+		notes:       make(map[string]string),
+		attachments: make(map[string]string),
+		names:       make(map[string][]string),
+		resources:   make(map[string]string),
+	}
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if strings.HasPrefix(d.Name(), ".") && p != dir {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+
+		if filepath.Ext(rel) == ".md" {
+			files = append(files, rel)
+		} else {
+			key := strings.ToLower(filepath.ToSlash(rel))
+
+			v.attachments[key] = rel
+			v.names[path.Base(key)] = append(v.names[path.Base(key)], rel)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		id := NewItemID()
+
+		v.notes[vaultKey(file)] = id
+
+		if _, ok := v.notes[path.Base(vaultKey(file))]; !ok {
+			v.notes[path.Base(vaultKey(file))] = id
+		}
+	}
+
+	if len(notebookID) == 0 {
+		notebook, err := c.CreateNotebook(Notebook{Title: filepath.Base(filepath.Clean(dir))})
+		if err != nil {
+			return nil, err
+		}
+
+		notebookID = notebook.ID
+	}
+
+	notebooks := map[string]string{".": notebookID}
+
+	var notebookFor func(string) (string, error)
+
+	notebookFor = func(rel string) (string, error) {
+		if id, ok := notebooks[rel]; ok {
+			return id, nil
+		}
+
+		parentID, err := notebookFor(filepath.Dir(rel))
+		if err != nil {
+			return "", err
+		}
+
+		notebook, err := c.CreateNotebook(Notebook{
+			ParentID: parentID,
+			Title:    filepath.Base(rel),
+		})
+		if err != nil {
+			return "", err
+		}
+
+		notebooks[rel] = notebook.ID
+
+		return notebook.ID, nil
+	}
+
+	var created []Note
+
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			return created, err
+		}
+
+		info, err := os.Stat(filepath.Join(dir, file))
+		if err != nil {
+			return created, err
+		}
+
+		parentID, err := notebookFor(filepath.Dir(file))
+		if err != nil {
+			return created, err
+		}
+
+		body, err := c.convertVaultLinks(&v, filepath.Dir(file), string(content))
+		if err != nil {
+			return created, err
+		}
+
+		modTime := int(info.ModTime().UnixMilli())
+
+		note, err := c.CreateNoteItem(Note{
+			ID:              v.notes[vaultKey(file)],
+			ParentID:        parentID,
+			Title:           strings.TrimSuffix(filepath.Base(file), ".md"),
+			Body:            body,
+			UserCreatedTime: modTime,
+			UserUpdatedTime: modTime,
+		})
+		if err != nil {
+			return created, err
+		}
+
+		for _, title := range InlineTags(string(content)) {
+			tag, err := c.FindOrCreateTag(title)
+			if err != nil {
+				return created, err
+			}
+
+			err = c.AddTagToNote(tag.ID, note)
+			if err != nil {
+				return created, err
+			}
+		}
+
+		created = append(created, note)
+	}
+
+	return created, nil
+}
+
+func vaultFilename(title string) string {
+	name := strings.TrimSpace(badFilenameChars.Replace(title))
+	if len(name) == 0 {
+		name = "Untitled"
+	}
+
+	return name
+}
+
+// uniqueFilename numbers the name like 'name (2).ext' if it is already used
+// and marks the result as used.
+func uniqueFilename(name string, used map[string]bool) string {
+	ext := path.Ext(name)
+	unique := name
+
+	for i := 2; used[strings.ToLower(unique)]; i++ {
+		unique = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), i, ext)
+	}
+
+	used[strings.ToLower(unique)] = true
+
+	return unique
+}
+
+// exportVaultLinks turns the links to exported notes into wikilinks to the
+// path returned by noteTarget and links to resources into wikilinks to the
+// file name returned by attachment.
+func exportVaultLinks(body string, noteTarget func(id string) (string, bool), attachment func(id string) (string, error)) (string, error) {
+	var linkErr error
+
+	body = markdownItemLinkRegexp.ReplaceAllStringFunc(body, func(link string) string {
+		m := markdownItemLinkRegexp.FindStringSubmatch(link)
+		embed, text, id, anchor := m[1], m[2], m[3], m[4]
+
+		if target, ok := noteTarget(id); ok {
+			if len(text) == 0 || text == path.Base(target) {
+				return fmt.Sprintf("[[%s%s]]", target, anchor)
+			}
+
+			return fmt.Sprintf("[[%s%s|%s]]", target, anchor, text)
+		}
+
+		name, err := attachment(id)
+		if isNotFound(err) {
+			// Links to notes which are not exported end up here, too.
+			return link
+		}
+
+		if err != nil {
+			linkErr = err
+
+			return link
+		}
+
+		if embed == "!" {
+			return fmt.Sprintf("![[%s]]", name)
+		}
+
+		if len(text) == 0 || text == name {
+			return fmt.Sprintf("[[%s]]", name)
+		}
+
+		return fmt.Sprintf("[[%s|%s]]", name, text)
+	})
+
+	return body, linkErr
+}
+
+// ExportObsidianVault writes the given notes as Markdown files into dir.
+// Notebooks below the notebook with the given root ID become folders, links
+// between exported notes become wikilinks and resources are stored in the
+// 'attachments' folder. Tags are appended as '#tags' unless already present.
+func (c *Client) ExportObsidianVault(dir string, notes []Note, rootID string) error {
+	notebooks, err := c.GetAllNotebooks("id,parent_id,title", "", "")
+	if err != nil {
+		return err
+	}
+
+	notebooksByID := make(map[string]Notebook)
+	for _, notebook := range notebooks {
+		notebooksByID[notebook.ID] = notebook
+	}
+
+	folderOf := func(notebookID string) string {
+		var parts []string
+
+		for id := notebookID; len(id) != 0 && id != rootID; {
+			notebook, ok := notebooksByID[id]
+			if !ok {
+				break
+			}
+
+			parts = append([]string{vaultFilename(notebook.Title)}, parts...)
+			id = notebook.ParentID
+		}
+
+		return path.Join(parts...)
+	}
+
+	// Every note gets a unique file name, links use the full path only if
+	// the title alone is ambiguous.
+	files := make(map[string]string)
+	used := make(map[string]bool)
+	titles := make(map[string]int)
+	ambiguous := make(map[string]bool)
+
+	for _, note := range notes {
+		titles[strings.ToLower(vaultFilename(note.Title))]++
+	}
+
+	for _, note := range notes {
+		base := path.Join(folderOf(note.ParentID), vaultFilename(note.Title))
+		name := base
+
+		for i := 2; used[strings.ToLower(name)]; i++ {
+			name = fmt.Sprintf("%s (%d)", base, i)
+		}
+
+		used[strings.ToLower(name)] = true
+		files[note.ID] = name
+		ambiguous[note.ID] = titles[strings.ToLower(vaultFilename(note.Title))] > 1
+	}
+
+	linkTarget := func(id string) (string, bool) {
+		file, ok := files[id]
+		if !ok || ambiguous[id] {
+			return file, ok
+		}
+
+		return path.Base(file), true
+	}
+
+	attachments := make(map[string]string)
+	usedAttachments := make(map[string]bool)
+
+	attachment := func(id string) (string, error) {
+		if name, ok := attachments[id]; ok {
+			return name, nil
+		}
+
+		resource, err := c.GetResource(id, AllResourceFields)
+		if err != nil {
+			return "", err
+		}
+
+		data, err := c.GetResourceFile(id)
+		if err != nil {
+			return "", err
+		}
+
+		name := resource.Filename
+		if len(name) == 0 {
+			name = resource.Title
+		}
+
+		if len(name) == 0 {
+			name = resourceFilename(resource)
+		}
+
+		name = uniqueFilename(vaultFilename(name), usedAttachments)
+
+		err = os.MkdirAll(filepath.Join(dir, "attachments"), 0755)
+		if err != nil {
+			return "", err
+		}
+
+		err = os.WriteFile(filepath.Join(dir, "attachments", name), data, 0644)
+		if err != nil {
+			return "", err
+		}
+
+		attachments[id] = name
+
+		return name, nil
+	}
+
+	for _, note := range notes {
+		body, err := exportVaultLinks(note.Body, linkTarget, attachment)
+		if err != nil {
+			return err
+		}
+
+		tags, err := c.GetNoteTags(note.ID)
+		if err != nil {
+			return err
+		}
+
+		present := make(map[string]bool)
+		for _, tag := range InlineTags(body) {
+			present[strings.ToLower(tag)] = true
+		}
+
+		var missing []string
+
+		for _, tag := range tags {
+			title := strings.ReplaceAll(tag.Title, " ", "-")
+
+			if !present[strings.ToLower(title)] {
+				missing = append(missing, "#"+title)
+			}
+		}
+
+		if len(missing) != 0 {
+			body = strings.TrimRight(body, "\n") + "\n\n" + strings.Join(missing, " ") + "\n"
+		}
+
+		file := filepath.Join(dir, filepath.FromSlash(files[note.ID])+".md")
+
+		err = os.MkdirAll(filepath.Dir(file), 0755)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(file, []byte(body), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,308 @@
+package goplin
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestInlineTags(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "no tags",
+			body: "# Heading\ntext",
+		},
+		{
+			name: "tags in text",
+			body: "#start of a line and #inline #with-dash #nested/tag/\n",
+			want: []string{"start", "inline", "with-dash", "nested/tag"},
+		},
+		{
+			name: "duplicates keep the first spelling",
+			body: "#Work and #work and #WORK",
+			want: []string{"Work"},
+		},
+		{
+			name: "numeric tags and headings are ignored",
+			body: "# Heading\n## Sub\nissue #42 and #2022-08 #v2",
+			want: []string{"2022-08", "v2"},
+		},
+		{
+			name: "tags in code blocks are ignored",
+			body: "```\n#include <stdio.h>\n```\n#real",
+			want: []string{"real"},
+		},
+		{
+			name: "anchors and words are no tags",
+			body: "see [x](#anchor) and a#b",
+		},
+		{
+			name: "unicode",
+			body: "#café #日本",
+			want: []string{"café", "日本"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InlineTags(tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("InlineTags(%q) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertVaultLinks(t *testing.T) {
+	const (
+		projectID = "11111111111111111111111111111111"
+		dailyID   = "22222222222222222222222222222222"
+		imageID   = "33333333333333333333333333333333"
+		noteImgID = "44444444444444444444444444444444"
+		pdfID     = "55555555555555555555555555555555"
+	)
+
+	// The resources are already known, so no request is sent.
+	v := &obsidianVault{
+		notes: map[string]string{
+			"project":          projectID,
+			"daily/2022-08-01": dailyID,
+			"2022-08-01":       dailyID,
+		},
+		attachments: map[string]string{
+			"img/a.png":   "img/a.png",
+			"notes/a.png": "notes/a.png",
+			"doc.pdf":     "doc.pdf",
+		},
+		names: map[string][]string{
+			"a.png":   {"img/a.png", "notes/a.png"},
+			"doc.pdf": {"doc.pdf"},
+		},
+		resources: map[string]string{
+			"img/a.png":   imageID,
+			"notes/a.png": noteImgID,
+			"doc.pdf":     pdfID,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		noteDir string
+		body    string
+		want    string
+	}{
+		{
+			name:    "note",
+			noteDir: ".",
+			body:    "see [[Project]]",
+			want:    "see [Project](:/" + projectID + ")",
+		},
+		{
+			name:    "note by path",
+			noteDir: ".",
+			body:    "[[daily/2022-08-01.md]] [[2022-08-01]]",
+			want:    "[2022-08-01](:/" + dailyID + ") [2022-08-01](:/" + dailyID + ")",
+		},
+		{
+			name:    "alias",
+			noteDir: ".",
+			body:    "[[Project|the plan]]",
+			want:    "[the plan](:/" + projectID + ")",
+		},
+		{
+			name:    "heading",
+			noteDir: ".",
+			body:    "[[Project#Next Steps]] [[Project#Next Steps|next]]",
+			want:    "[Project](:/" + projectID + "#next-steps) [next](:/" + projectID + "#next-steps)",
+		},
+		{
+			name:    "heading in the same note",
+			noteDir: ".",
+			body:    "[[#Open Questions]] [[#Open Questions|below]]",
+			want:    "[Open Questions](#open-questions) [below](#open-questions)",
+		},
+		{
+			name:    "embedded note becomes a link",
+			noteDir: ".",
+			body:    "![[Project]]",
+			want:    "[Project](:/" + projectID + ")",
+		},
+		{
+			name:    "unknown note is kept",
+			noteDir: ".",
+			body:    "[[Missing]] [x](Missing.md)",
+			want:    "[[Missing]] [x](Missing.md)",
+		},
+		{
+			name:    "embedded image relative to the note",
+			noteDir: "notes",
+			body:    "![[a.png]]",
+			want:    "![](:/" + noteImgID + ")",
+		},
+		{
+			name:    "embedded image relative to the vault",
+			noteDir: "notes",
+			body:    "![[img/a.png|a diagram]]",
+			want:    "![a diagram](:/" + imageID + ")",
+		},
+		{
+			name:    "ambiguous attachment name is kept",
+			noteDir: ".",
+			body:    "![[a.png]]",
+			want:    "![[a.png]]",
+		},
+		{
+			name:    "unique attachment name",
+			noteDir: "notes",
+			body:    "[[doc.pdf]] ![[doc.pdf]]",
+			want:    "[doc.pdf](:/" + pdfID + ") [doc.pdf](:/" + pdfID + ")",
+		},
+		{
+			name:    "Markdown links",
+			noteDir: ".",
+			body:    "[plan](Project.md) ![](img/a.png) [pdf](doc.pdf)",
+			want:    "[plan](:/" + projectID + ") ![](:/" + imageID + ") [pdf](:/" + pdfID + ")",
+		},
+		{
+			name:    "external and anchor links are kept",
+			noteDir: ".",
+			body:    "[web](https://example.com/a.png) [top](#top) [id](:/" + pdfID + ")",
+			want:    "[web](https://example.com/a.png) [top](#top) [id](:/" + pdfID + ")",
+		},
+	}
+
+	c := &Client{}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.convertVaultLinks(v, tt.noteDir, tt.body)
+			if err != nil {
+				t.Fatalf("convertVaultLinks: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("convertVaultLinks(%q) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportVaultLinks(t *testing.T) {
+	const (
+		projectID = "11111111111111111111111111111111"
+		dailyID   = "22222222222222222222222222222222"
+		imageID   = "33333333333333333333333333333333"
+		otherID   = "44444444444444444444444444444444"
+		missingID = "55555555555555555555555555555555"
+		brokenID  = "66666666666666666666666666666666"
+	)
+
+	errBroken := errors.New("broken")
+
+	noteTarget := func(id string) (string, bool) {
+		target, ok := map[string]string{
+			projectID: "Project",
+			dailyID:   "daily/2022-08-01",
+		}[id]
+
+		return target, ok
+	}
+
+	attachment := func(id string) (string, error) {
+		switch id {
+		case imageID:
+			return "a.png", nil
+		case otherID:
+			return "a (2).png", nil
+		case brokenID:
+			return "", errBroken
+		}
+
+		return "", &NotFoundError{Type: "resource", ID: id}
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "note",
+			body: "see [Project](:/" + projectID + ")",
+			want: "see [[Project]]",
+		},
+		{
+			name: "note by path",
+			body: "[2022-08-01](:/" + dailyID + ")",
+			want: "[[daily/2022-08-01]]",
+		},
+		{
+			name: "alias",
+			body: "[the plan](:/" + projectID + ")",
+			want: "[[Project|the plan]]",
+		},
+		{
+			name: "anchor",
+			body: "[Project](:/" + projectID + "#next-steps) [next](:/" + projectID + "#next-steps)",
+			want: "[[Project#next-steps]] [[Project#next-steps|next]]",
+		},
+		{
+			name: "embedded attachments",
+			body: "![](:/" + imageID + ") ![other](:/" + otherID + ")",
+			want: "![[a.png]] ![[a (2).png]]",
+		},
+		{
+			name: "attachment links",
+			body: "[a.png](:/" + imageID + ") [second image](:/" + otherID + ")",
+			want: "[[a.png]] [[a (2).png|second image]]",
+		},
+		{
+			name: "note which is not exported",
+			body: "[elsewhere](:/" + missingID + ")",
+			want: "[elsewhere](:/" + missingID + ")",
+		},
+		{
+			name:    "failed attachment",
+			body:    "![](:/" + brokenID + ")",
+			wantErr: errBroken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := exportVaultLinks(tt.body, noteTarget, attachment)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("exportVaultLinks() error = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("exportVaultLinks: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("exportVaultLinks(%q) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUniqueFilename(t *testing.T) {
+	used := make(map[string]bool)
+
+	names := []string{"a.png", "A.png", "a.png", "b", "b", "a (2).png"}
+	want := []string{"a.png", "A (2).png", "a (3).png", "b", "b (2)", "a (2) (2).png"}
+
+	for i, name := range names {
+		if got := uniqueFilename(name, used); got != want[i] {
+			t.Errorf("uniqueFilename(%q) = %q, want %q", name, got, want[i])
+		}
+	}
+}
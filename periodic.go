@@ -0,0 +1,245 @@
+package goplin
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/a8m/envsubst/parse"
+)
+
+type Period int
+
+const (
+	Daily Period = iota
+	Weekly
+	Monthly
+)
+
+// PeriodicNoteOptions describes where a periodic note is stored and how it
+// is created. The notebook path, the title and the template may use the
+// variables ${DATE}, ${YEAR}, ${MONTH}, ${MONTH_NAME}, ${DAY}, ${WEEKDAY},
+// ${WEEK} and ${WEEK_YEAR}. The template may additionally use ${TITLE},
+// ${PREV_TITLE}, ${NEXT_TITLE}, ${PREV_LINK} and ${NEXT_LINK}, as well as all
+// environment variables.
+type PeriodicNoteOptions struct {
+	Period       Period
+	Date         time.Time
+	NotebookPath string
+	Title        string
+	Template     string
+}
+
+func (p Period) String() string {
+	switch p {
+	case Daily:
+		return "daily"
+	case Weekly:
+		return "weekly"
+	case Monthly:
+		return "monthly"
+	}
+
+	return "unknown"
+}
+
+// Start returns the first day of the period containing t.
+func (p Period) Start(t time.Time) time.Time {
+	t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+
+	switch p {
+	case Weekly:
+		// Weeks start on Monday as in ISO 8601.
+		return t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+	case Monthly:
+		return t.AddDate(0, 0, 1-t.Day())
+	}
+
+	return t
+}
+
+// Add moves t by n periods.
+func (p Period) Add(t time.Time, n int) time.Time {
+	switch p {
+	case Weekly:
+		return t.AddDate(0, 0, 7*n)
+	case Monthly:
+		return t.AddDate(0, n, 0)
+	}
+
+	return t.AddDate(0, 0, n)
+}
+
+// DefaultNotebookPath returns the notebook path used if none is configured.
+func (p Period) DefaultNotebookPath() string {
+	if p == Daily {
+		return "Journal/${YEAR}/${MONTH}"
+	}
+
+	return "Journal/${YEAR}"
+}
+
+// DefaultTitle returns the title used if none is configured.
+func (p Period) DefaultTitle() string {
+	switch p {
+	case Weekly:
+		return "${WEEK_YEAR}-W${WEEK}"
+	case Monthly:
+		return "${YEAR}-${MONTH}"
+	}
+
+	return "${DATE}"
+}
+
+func dateVariables(t time.Time) []string {
+	// The year of an ISO week may differ from the calendar year around new
+	// year.
+	weekYear, week := t.ISOWeek()
+
+	return []string{
+		"DATE=" + t.Format("2006-01-02"),
+		"YEAR=" + t.Format("2006"),
+		"WEEK_YEAR=" + fmt.Sprintf("%04d", weekYear),
+		"MONTH=" + t.Format("01"),
+		"MONTH_NAME=" + t.Format("January"),
+		"DAY=" + t.Format("02"),
+		"WEEKDAY=" + t.Format("Monday"),
+		"WEEK=" + fmt.Sprintf("%02d", week),
+	}
+}
+
+// ExpandVariables replaces the ${NAME} variables in s. The given variables
+// take precedence over the environment.
+func ExpandVariables(s string, variables []string) (string, error) {
+	env := append(append([]string{}, variables...), os.Environ()...)
+
+	return parse.New("string", env, &parse.Restrictions{}).Parse(s)
+}
+
+func (c *Client) findNoteInNotebook(notebookID string, title string) (Note, bool, error) {
+	notes, err := c.GetNotesInNotebook(notebookID, "id,parent_id,title", "", "")
+	if err != nil {
+		return Note{}, false, err
+	}
+
+	for _, note := range notes {
+		if note.Title == title {
+			return note, true, nil
+		}
+	}
+
+	return Note{}, false, nil
+}
+
+func periodicNoteLocation(opts PeriodicNoteOptions, t time.Time) (string, string, error) {
+	variables := dateVariables(t)
+
+	notebookPath, err := ExpandVariables(opts.NotebookPath, variables)
+	if err != nil {
+		return "", "", err
+	}
+
+	title, err := ExpandVariables(opts.Title, variables)
+	if err != nil {
+		return "", "", err
+	}
+
+	return notebookPath, title, nil
+}
+
+func (c *Client) periodicNoteLink(opts PeriodicNoteOptions, t time.Time) (string, string, error) {
+	notebookPath, title, err := periodicNoteLocation(opts, t)
+	if err != nil {
+		return "", "", err
+	}
+
+	notebook, err := c.NotebookByPath(notebookPath, false)
+	if err != nil {
+		return title, title, nil
+	}
+
+	note, found, err := c.findNoteInNotebook(notebook.ID, title)
+	if err != nil || !found {
+		return title, title, err
+	}
+
+	return title, fmt.Sprintf("[%s](:/%s)", title, note.ID), nil
+}
+
+// PeriodicNote returns the note of the period containing the date of the
+// options. If it does not exist yet, it is created from the template and
+// the second return value is true.
+func (c *Client) PeriodicNote(opts PeriodicNoteOptions) (Note, bool, error) {
+	if len(opts.NotebookPath) == 0 {
+		opts.NotebookPath = opts.Period.DefaultNotebookPath()
+	}
+
+	if len(opts.Title) == 0 {
+		opts.Title = opts.Period.DefaultTitle()
+	}
+
+	if opts.Date.IsZero() {
+		opts.Date = time.Now()
+	}
+
+	start := opts.Period.Start(opts.Date)
+
+	notebookPath, title, err := periodicNoteLocation(opts, start)
+	if err != nil {
+		return Note{}, false, err
+	}
+
+	notebook, err := c.NotebookByPath(notebookPath, true)
+	if err != nil {
+		return Note{}, false, err
+	}
+
+	note, found, err := c.findNoteInNotebook(notebook.ID, title)
+	if err != nil || found {
+		return note, false, err
+	}
+
+	template := "# ${TITLE}\n\n${PREV_LINK} | ${NEXT_LINK}\n"
+
+	if len(opts.Template) != 0 {
+		template, err = ExpandBody("@" + opts.Template)
+		if err != nil {
+			return Note{}, false, err
+		}
+	}
+
+	prevTitle, prevLink, err := c.periodicNoteLink(opts, opts.Period.Add(start, -1))
+	if err != nil {
+		return Note{}, false, err
+	}
+
+	nextTitle, nextLink, err := c.periodicNoteLink(opts, opts.Period.Add(start, 1))
+	if err != nil {
+		return Note{}, false, err
+	}
+
+	variables := append(dateVariables(start),
+		"TITLE="+title,
+		"PREV_TITLE="+prevTitle,
+		"NEXT_TITLE="+nextTitle,
+		"PREV_LINK="+prevLink,
+		"NEXT_LINK="+nextLink,
+	)
+
+	body, err := ExpandVariables(template, variables)
+	if err != nil {
+		return Note{}, false, err
+	}
+
+	note, err = c.CreateNoteItem(Note{
+		ParentID: notebook.ID,
+		Title:    title,
+		Body:     strings.TrimLeft(body, "\n"),
+	})
+	if err != nil {
+		return Note{}, false, err
+	}
+
+	return note, true, nil
+}
@@ -0,0 +1,190 @@
+package goplin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imroc/req/v3"
+)
+
+// DefaultRetries is the number of retries of idempotent requests used by New.
+const DefaultRetries = 3
+
+// DefaultRetryWait is the wait before the first retry, it doubles with every
+// further retry.
+const DefaultRetryWait = 250 * time.Millisecond
+
+const maxRetryWait = 10 * time.Second
+
+// ResponseError is returned if Joplin answers with an error or an unexpected
+// status.
+type ResponseError struct {
+	StatusCode int
+	Status     string
+	Dump       string
+}
+
+func (e *ResponseError) Error() string {
+	if e.StatusCode >= 400 {
+		return fmt.Sprintf("got error response:\n%s\n%s", e.Status, e.Dump)
+	}
+
+	return fmt.Sprintf("got unexpected response, raw dump:\n%s", e.Dump)
+}
+
+func isStatus(err error, statusCode int) bool {
+	var respErr *ResponseError
+
+	return errors.As(err, &respErr) && respErr.StatusCode == statusCode
+}
+
+func isNotFound(err error) bool {
+	var notFound *NotFoundError
+
+	return isStatus(err, http.StatusNotFound) || errors.As(err, &notFound)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodOptions:
+		return true
+	}
+
+	return false
+}
+
+// shouldRetry retries failed connections, e.g. reset by Joplin while it syncs
+// or starts, and server errors.
+func shouldRetry(resp *req.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// limiter limits the number of concurrent requests and the rate at which they
+// are started.
+type limiter struct {
+	slots    chan struct{}
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newLimiter(maxConcurrent int, rate float64) *limiter {
+	l := &limiter{}
+
+	if maxConcurrent > 0 {
+		l.slots = make(chan struct{}, maxConcurrent)
+	}
+
+	if rate > 0 {
+		l.interval = time.Duration(float64(time.Second) / rate)
+	}
+
+	return l
+}
+
+func (l *limiter) acquire() {
+	if l.slots != nil {
+		l.slots <- struct{}{}
+	}
+
+	if l.interval == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+
+	if l.next.Before(now) {
+		l.next = now
+	}
+
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	time.Sleep(wait)
+}
+
+func (l *limiter) release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+func (l *limiter) wrap(rt req.RoundTripper) req.RoundTripFunc {
+	return func(r *req.Request) (*req.Response, error) {
+		l.acquire()
+		defer l.release()
+
+		return rt.RoundTrip(r)
+	}
+}
+
+// send sends the request to the path of the Data API, e.g. '/notes/{id}',
+// with the token. Idempotent requests are retried with exponential backoff
+// and jitter. Error responses are returned as ResponseError.
+func (c *Client) send(r *req.Request, method string, path string) (*req.Response, error) {
+	if len(c.apiToken) != 0 {
+		r.SetQueryParam("token", c.apiToken)
+	}
+
+	if c.retries > 0 && isIdempotent(method) {
+		r.SetRetryCount(c.retries).
+			SetRetryBackoffInterval(c.retryWait, maxRetryWait).
+			SetRetryCondition(shouldRetry)
+	}
+
+	resp, err := r.Send(method, fmt.Sprintf("http://%s:%d%s", c.host, c.port, path))
+	if err != nil {
+		return resp, c.redactError(err)
+	}
+
+	if !resp.IsSuccess() {
+		return resp, &ResponseError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Dump:       c.dump(resp),
+		}
+	}
+
+	return resp, nil
+}
+
+// Do sends a request to any endpoint of the Data API, e.g. to the revisions
+// or with query parameters the other methods do not support. The path is
+// relative to the Web Clipper service, like "/revisions" or "/notes/<id>".
+// The token is added to the query. body is sent as JSON unless it is nil,
+// a string or a []byte, and a successful JSON answer is decoded into out
+// unless it is nil. Retries, limits and errors are handled like for all other
+// requests, error responses are returned as ResponseError.
+func (c *Client) Do(ctx context.Context, method string, path string, query map[string]string, body interface{}, out interface{}) error {
+	r := c.handle.R().
+		SetContext(ctx).
+		SetQueryParams(query)
+
+	if body != nil {
+		r.SetBody(body)
+	}
+
+	if out != nil {
+		r.SetResult(out)
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	_, err := c.send(r, strings.ToUpper(method), path)
+
+	return err
+}
@@ -0,0 +1,454 @@
+package goplin
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	AllNoteFields     = "id,parent_id,title,body,created_time,updated_time,is_conflict,latitude,longitude,altitude,author,source_url,is_todo,todo_due,todo_completed,source,source_application,application_data,order,user_created_time,user_updated_time,markup_language"
+	AllNotebookFields = "id,parent_id,title,created_time,updated_time,user_created_time,user_updated_time,icon"
+	AllTagFields      = "id,parent_id,title,created_time,updated_time,user_created_time,user_updated_time"
+	AllResourceFields = "id,title,mime,filename,created_time,updated_time,user_created_time,user_updated_time,file_extension,size"
+)
+
+// Selection describes a subset of notes. All criteria which are set have to
+// match.
+type Selection struct {
+	Notebook  string
+	Tag       string
+	Query     string
+	Recursive bool
+}
+
+// NotFoundError is returned if an item looked up by name or ID does not
+// exist.
+type NotFoundError struct {
+	Type string
+	Name string
+	ID   string
+}
+
+func (e *NotFoundError) Error() string {
+	if len(e.ID) != 0 {
+		return fmt.Sprintf("could not find %s with ID '%s'", e.Type, e.ID)
+	}
+
+	return fmt.Sprintf("could not find %s called '%s'", e.Type, e.Name)
+}
+
+var itemIDRegexp = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// IsItemID reports whether s looks like a Joplin item ID.
+func IsItemID(s string) bool {
+	return itemIDRegexp.MatchString(s)
+}
+
+// NewItemID returns a random ID in the format used by Joplin.
+func NewItemID() string {
+	b := make([]byte, 16)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+func (c *Client) findItem(nameOrID string, itemType string) (Item, error) {
+	items, err := c.Search(nameOrID, itemType, "id,parent_id,title")
+	if err != nil {
+		return Item{}, err
+	}
+
+	if len(items) == 1 {
+		return items[0], nil
+	}
+
+	var matches []Item
+
+	for _, item := range items {
+		if strings.EqualFold(item.Title, nameOrID) {
+			matches = append(matches, item)
+		}
+	}
+
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+
+	if len(matches) > 1 {
+		return Item{}, fmt.Errorf("found more than one %s called '%s'", itemType, nameOrID)
+	}
+
+	return Item{}, &NotFoundError{Type: itemType, Name: nameOrID}
+}
+
+func (c *Client) FindNotebook(nameOrID string) (Notebook, error) {
+	if IsItemID(nameOrID) {
+		notebook, err := c.GetNotebook(nameOrID, AllNotebookFields)
+		if err == nil {
+			return notebook, nil
+		}
+	}
+
+	item, err := c.findItem(nameOrID, ItemTypeFolder)
+	if err != nil {
+		return Notebook{}, err
+	}
+
+	return c.GetNotebook(item.ID, AllNotebookFields)
+}
+
+func (c *Client) FindTag(nameOrID string) (Tag, error) {
+	if IsItemID(nameOrID) {
+		tag, err := c.GetTag(nameOrID, AllTagFields)
+		if err == nil {
+			return tag, nil
+		}
+	}
+
+	item, err := c.findItem(nameOrID, ItemTypeTag)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	return c.GetTag(item.ID, AllTagFields)
+}
+
+// FindOrCreateTag returns the tag with the given title, creating it if it
+// does not exist yet.
+func (c *Client) FindOrCreateTag(title string) (Tag, error) {
+	tag, err := c.FindTag(title)
+	if err == nil && strings.EqualFold(tag.Title, title) {
+		return tag, nil
+	}
+
+	var notFound *NotFoundError
+
+	if err != nil && !errors.As(err, &notFound) {
+		return Tag{}, err
+	}
+
+	return c.CreateTag(Tag{Title: title})
+}
+
+// FindTags looks up the tags with the given titles. The titles of the tags
+// which do not exist are returned separately.
+func (c *Client) FindTags(titles []string) ([]Tag, []string, error) {
+	var tags []Tag
+	var missing []string
+
+	for _, title := range titles {
+		tag, err := c.FindTag(title)
+		if err == nil && strings.EqualFold(tag.Title, title) {
+			tags = append(tags, tag)
+
+			continue
+		}
+
+		var notFound *NotFoundError
+
+		if err != nil && !errors.As(err, &notFound) {
+			return nil, nil, err
+		}
+
+		missing = append(missing, title)
+	}
+
+	return tags, missing, nil
+}
+
+// CreateTags creates tags with the given titles.
+func (c *Client) CreateTags(titles []string) ([]Tag, error) {
+	var tags []Tag
+
+	for _, title := range titles {
+		tag, err := c.CreateTag(Tag{Title: title})
+		if err != nil {
+			return tags, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+func (c *Client) FindNote(titleOrID string) (Note, error) {
+	if IsItemID(titleOrID) {
+		note, err := c.GetNote(titleOrID, AllNoteFields)
+		if err == nil {
+			return note, nil
+		}
+	}
+
+	items, err := c.Search(fmt.Sprintf("title:\"%s\"", titleOrID), "note", "id,parent_id,title")
+	if err != nil {
+		return Note{}, err
+	}
+
+	var matches []Item
+
+	for _, item := range items {
+		if strings.EqualFold(item.Title, titleOrID) {
+			matches = append(matches, item)
+		}
+	}
+
+	if len(matches) == 0 && len(items) == 1 {
+		matches = items
+	}
+
+	if len(matches) == 0 {
+		return Note{}, fmt.Errorf("could not find note called '%s'", titleOrID)
+	}
+
+	if len(matches) > 1 {
+		return Note{}, fmt.Errorf("found more than one note called '%s'", titleOrID)
+	}
+
+	return c.GetNote(matches[0].ID, AllNoteFields)
+}
+
+// NotebookByPath returns the notebook with the given path of titles separated
+// by '/', e.g. "Journal/2026/10". Missing notebooks are created if create is
+// set.
+func (c *Client) NotebookByPath(notebookPath string, create bool) (Notebook, error) {
+	notebooks, err := c.GetAllNotebooks(AllNotebookFields, "", "")
+	if err != nil {
+		return Notebook{}, err
+	}
+
+	var current Notebook
+
+	for _, title := range strings.Split(strings.Trim(notebookPath, "/"), "/") {
+		title = strings.TrimSpace(title)
+		if len(title) == 0 {
+			continue
+		}
+
+		found := false
+
+		for _, notebook := range notebooks {
+			if notebook.ParentID == current.ID && strings.EqualFold(notebook.Title, title) {
+				current = notebook
+				found = true
+
+				break
+			}
+		}
+
+		if found {
+			continue
+		}
+
+		if !create {
+			return Notebook{}, &NotFoundError{Type: "notebook", Name: notebookPath}
+		}
+
+		current, err = c.CreateNotebook(Notebook{
+			ParentID: current.ID,
+			Title:    title,
+		})
+		if err != nil {
+			return Notebook{}, err
+		}
+
+		notebooks = append(notebooks, current)
+	}
+
+	if len(current.ID) == 0 {
+		return Notebook{}, fmt.Errorf("invalid notebook path '%s'", notebookPath)
+	}
+
+	return current, nil
+}
+
+// ResolveNotebook returns the notebook with the given ID, name or path like
+// "Work/Meetings". Missing notebooks of the path are created if create is
+// set.
+func (c *Client) ResolveNotebook(notebook string, create bool) (Notebook, error) {
+	var result Notebook
+	var err error
+
+	if strings.Contains(notebook, "/") {
+		result, err = c.NotebookByPath(notebook, false)
+	} else {
+		result, err = c.FindNotebook(notebook)
+	}
+
+	var notFound *NotFoundError
+
+	if create && errors.As(err, &notFound) {
+		return c.NotebookByPath(notebook, true)
+	}
+
+	return result, err
+}
+
+// NotebookPaths returns the paths of the notebooks by ID, e.g.
+// "Journal/2026/10".
+func NotebookPaths(notebooks []Notebook) map[string]string {
+	byID := make(map[string]Notebook, len(notebooks))
+	for _, notebook := range notebooks {
+		byID[notebook.ID] = notebook
+	}
+
+	paths := make(map[string]string, len(notebooks))
+
+	for _, notebook := range notebooks {
+		path := notebook.Title
+
+		// The depth limit protects against cycles.
+		for parent, depth := notebook.ParentID, 0; len(parent) != 0 && depth < len(notebooks); depth++ {
+			p, ok := byID[parent]
+			if !ok {
+				break
+			}
+
+			path = p.Title + "/" + path
+			parent = p.ParentID
+		}
+
+		paths[notebook.ID] = path
+	}
+
+	return paths
+}
+
+// SubNotebookIDs returns the ID of the given notebook followed by the IDs of
+// all notebooks below it.
+func SubNotebookIDs(notebooks []Notebook, id string) []string {
+	ids := []string{id}
+
+	for i := 0; i < len(ids); i++ {
+		for _, notebook := range notebooks {
+			if notebook.ParentID == ids[i] {
+				ids = append(ids, notebook.ID)
+			}
+		}
+	}
+
+	return ids
+}
+
+func (c *Client) selectNoteIDs(sel Selection) ([][]string, error) {
+	var sets [][]string
+
+	if len(sel.Notebook) != 0 {
+		notebook, err := c.FindNotebook(sel.Notebook)
+		if err != nil {
+			return nil, err
+		}
+
+		notebookIDs := []string{notebook.ID}
+
+		if sel.Recursive {
+			notebooks, err := c.GetAllNotebooks("id,parent_id,title", "", "")
+			if err != nil {
+				return nil, err
+			}
+
+			notebookIDs = SubNotebookIDs(notebooks, notebook.ID)
+		}
+
+		var ids []string
+
+		for _, notebookID := range notebookIDs {
+			notes, err := c.GetNotesInNotebook(notebookID, "id", "", "")
+			if err != nil {
+				return nil, err
+			}
+
+			for _, note := range notes {
+				ids = append(ids, note.ID)
+			}
+		}
+
+		sets = append(sets, ids)
+	}
+
+	if len(sel.Tag) != 0 {
+		tag, err := c.FindTag(sel.Tag)
+		if err != nil {
+			return nil, err
+		}
+
+		notes, err := c.GetNotesByTag(tag.ID, "", "")
+		if err != nil {
+			return nil, err
+		}
+
+		var ids []string
+
+		for _, note := range notes {
+			ids = append(ids, note.ID)
+		}
+
+		sets = append(sets, ids)
+	}
+
+	if len(sel.Query) != 0 {
+		items, err := c.Search(sel.Query, "note", "id")
+		if err != nil {
+			return nil, err
+		}
+
+		var ids []string
+
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+
+		sets = append(sets, ids)
+	}
+
+	return sets, nil
+}
+
+// SelectNotes returns the notes matching the selection with the given fields.
+// An empty selection matches all notes.
+func (c *Client) SelectNotes(sel Selection, fields string) ([]Note, error) {
+	sets, err := c.selectNoteIDs(sel)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sets) == 0 {
+		return c.GetAllNotes(fields, "", "")
+	}
+
+	var ids []string
+
+	for _, id := range sets[0] {
+		found := true
+
+		for _, set := range sets[1:] {
+			found = false
+
+			for _, other := range set {
+				if other == id {
+					found = true
+
+					break
+				}
+			}
+
+			if !found {
+				break
+			}
+		}
+
+		if found {
+			ids = append(ids, id)
+		}
+	}
+
+	return c.GetNotes(ids, fields)
+}
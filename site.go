@@ -0,0 +1,375 @@
+package goplin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// Markup languages of a note.
+const (
+	MarkupLanguageMarkdown = 1
+	MarkupLanguageHTML     = 2
+)
+
+var htmlTagRegexp = regexp.MustCompile(`<[^>]*>`)
+
+var siteMarkdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+const siteCSS = `body { margin: 0; font-family: sans-serif; line-height: 1.5; color: #222; }
+nav { position: fixed; top: 0; bottom: 0; left: 0; width: 18em; overflow-y: auto; padding: 1em; background: #f4f4f4; box-sizing: border-box; }
+nav ul { list-style: none; padding-left: 1em; margin: 0; }
+nav > ul { padding-left: 0; }
+nav .notebook { font-weight: bold; }
+nav input { width: 100%; box-sizing: border-box; margin-bottom: 1em; }
+main { margin-left: 18em; padding: 1em 2em; max-width: 50em; }
+img { max-width: 100%; }
+pre { background: #f4f4f4; padding: 0.5em; overflow-x: auto; }
+.tags a { margin-right: 0.5em; }
+`
+
+const siteSearchJS = `(function () {
+	var input = document.getElementById("search");
+	var results = document.getElementById("results");
+
+	function search() {
+		var terms = input.value.toLowerCase().split(/\s+/).filter(function (t) { return t.length > 0; });
+		results.innerHTML = "";
+
+		if (terms.length === 0) {
+			return;
+		}
+
+		goplinSearchIndex.forEach(function (entry) {
+			var text = (entry.title + " " + entry.tags.join(" ") + " " + entry.text).toLowerCase();
+
+			if (terms.every(function (t) { return text.indexOf(t) >= 0; })) {
+				var li = document.createElement("li");
+				var a = document.createElement("a");
+				a.href = entry.url;
+				a.textContent = entry.title;
+				li.appendChild(a);
+				results.appendChild(li);
+			}
+		});
+	}
+
+	var params = new URLSearchParams(window.location.search);
+	input.value = params.get("q") || "";
+	input.addEventListener("input", search);
+	search();
+})();
+`
+
+const siteTemplate = `{{define "page"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - {{.SiteTitle}}</title>
+<link rel="stylesheet" href="style.css">
+</head>
+<body>
+<nav>
+<form action="search.html"><input type="search" name="q" placeholder="Search"></form>
+<a href="index.html">{{.SiteTitle}}</a> · <a href="tags.html">Tags</a>
+{{template "tree" .Tree}}
+</nav>
+<main>
+<h1>{{.Title}}</h1>
+{{if .Tags}}<p class="tags">{{range .Tags}}<a href="tags.html#{{.ID}}">#{{.Title}}</a>{{end}}</p>{{end}}
+{{.Content}}
+</main>
+</body>
+</html>
+{{end}}
+{{define "tree"}}<ul>
+{{range .Notes}}<li><a href="{{.ID}}.html">{{.Title}}</a></li>
+{{end}}{{range .Children}}<li><span class="notebook">{{.Title}}</span>{{template "tree" .}}</li>
+{{end}}</ul>{{end}}
+`
+
+type siteTree struct {
+	Title    string
+	Notes    []Note
+	Children []*siteTree
+}
+
+type sitePage struct {
+	SiteTitle string
+	Title     string
+	Tags      []Tag
+	Tree      *siteTree
+	Content   template.HTML
+}
+
+type siteSearchEntry struct {
+	ID    string   `json:"id"`
+	Title string   `json:"title"`
+	URL   string   `json:"url"`
+	Tags  []string `json:"tags"`
+	Text  string   `json:"text"`
+}
+
+// RenderNoteHTML renders the body of a note to HTML.
+func RenderNoteHTML(note Note) (string, error) {
+	if note.MarkupLanguage == MarkupLanguageHTML {
+		return note.Body, nil
+	}
+
+	var buf bytes.Buffer
+
+	err := siteMarkdown.Convert([]byte(note.Body), &buf)
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// newSiteTree returns the navigation tree of the notebook with the given root
+// ID. Only the notebooks which are keys of notes are part of the tree.
+func newSiteTree(notebooks []Notebook, notes map[string][]Note, rootID string) *siteTree {
+	trees := make(map[string]*siteTree)
+
+	for id, notebookNotes := range notes {
+		trees[id] = &siteTree{Notes: notebookNotes}
+	}
+
+	for _, notebook := range notebooks {
+		tree, ok := trees[notebook.ID]
+		if !ok {
+			continue
+		}
+
+		tree.Title = notebook.Title
+
+		if parent, ok := trees[notebook.ParentID]; ok && notebook.ID != rootID {
+			parent.Children = append(parent.Children, tree)
+		}
+	}
+
+	for _, tree := range trees {
+		sort.Slice(tree.Children, func(i, j int) bool {
+			return strings.ToLower(tree.Children[i].Title) < strings.ToLower(tree.Children[j].Title)
+		})
+	}
+
+	return trees[rootID]
+}
+
+// ExportSite writes a static HTML site of the notebook with the given ID and
+// all its sub-notebooks into dir. Links between notes are resolved, resources
+// are copied and a search index is created, so that the site works offline.
+func (c *Client) ExportSite(dir string, notebookID string) error {
+	notebooks, err := c.GetAllNotebooks("id,parent_id,title", "", "")
+	if err != nil {
+		return err
+	}
+
+	root, err := c.GetNotebook(notebookID, "id,parent_id,title")
+	if err != nil {
+		return err
+	}
+
+	notesByNotebook := make(map[string][]Note)
+
+	var notes []Note
+
+	for _, id := range SubNotebookIDs(notebooks, notebookID) {
+		notebookNotes, err := c.GetNotesInNotebook(id, AllNoteFields, "title", "ASC")
+		if err != nil {
+			return err
+		}
+
+		notes = append(notes, notebookNotes...)
+
+		notesByNotebook[id] = notebookNotes
+	}
+
+	tree := newSiteTree(notebooks, notesByNotebook, notebookID)
+
+	err = os.MkdirAll(filepath.Join(dir, "resources"), 0755)
+	if err != nil {
+		return err
+	}
+
+	links := make(map[string]string)
+
+	for _, note := range notes {
+		links[note.ID] = note.ID + ".html"
+	}
+
+	tmpl := template.Must(template.New("site").Parse(siteTemplate))
+
+	writePage := func(name string, page sitePage) error {
+		page.SiteTitle = root.Title
+		page.Tree = tree
+
+		var buf bytes.Buffer
+
+		err := tmpl.ExecuteTemplate(&buf, "page", page)
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0644)
+	}
+
+	tagNotes := make(map[string][]Note)
+	tagsByID := make(map[string]Tag)
+
+	var index []siteSearchEntry
+
+	for _, note := range notes {
+		resources, err := c.GetNoteResources(note.ID, AllResourceFields)
+		if err != nil {
+			return err
+		}
+
+		for _, resource := range resources {
+			if _, ok := links[resource.ID]; ok {
+				continue
+			}
+
+			data, err := c.GetResourceFile(resource.ID)
+			if err != nil {
+				return err
+			}
+
+			name := "resources/" + resourceFilename(resource)
+
+			err = os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), data, 0644)
+			if err != nil {
+				return err
+			}
+
+			links[resource.ID] = name
+		}
+
+		// Links to notes outside of the site are left as they are.
+		note.Body = itemLinkRegexp.ReplaceAllStringFunc(note.Body, func(link string) string {
+			if target, ok := links[strings.TrimPrefix(link, ":/")]; ok {
+				return target
+			}
+
+			return link
+		})
+
+		content, err := RenderNoteHTML(note)
+		if err != nil {
+			return fmt.Errorf("could not render note '%s': %w", note.Title, err)
+		}
+
+		tags, err := c.GetNoteTags(note.ID)
+		if err != nil {
+			return err
+		}
+
+		var tagTitles []string
+
+		for _, tag := range tags {
+			tagsByID[tag.ID] = tag
+			tagNotes[tag.ID] = append(tagNotes[tag.ID], note)
+			tagTitles = append(tagTitles, tag.Title)
+		}
+
+		err = writePage(note.ID+".html", sitePage{
+			Title:   note.Title,
+			Tags:    tags,
+			Content: template.HTML(content),
+		})
+		if err != nil {
+			return err
+		}
+
+		index = append(index, siteSearchEntry{
+			ID:    note.ID,
+			Title: note.Title,
+			URL:   note.ID + ".html",
+			Tags:  tagTitles,
+			Text:  strings.Join(strings.Fields(htmlTagRegexp.ReplaceAllString(content, " ")), " "),
+		})
+	}
+
+	var tagIDs []string
+
+	for id := range tagsByID {
+		tagIDs = append(tagIDs, id)
+	}
+
+	sort.Slice(tagIDs, func(i, j int) bool {
+		return strings.ToLower(tagsByID[tagIDs[i]].Title) < strings.ToLower(tagsByID[tagIDs[j]].Title)
+	})
+
+	var tagIndex strings.Builder
+
+	for _, id := range tagIDs {
+		fmt.Fprintf(&tagIndex, "<h2 id=\"%s\">%s</h2>\n<ul>\n", id, template.HTMLEscapeString(tagsByID[id].Title))
+
+		for _, note := range tagNotes[id] {
+			fmt.Fprintf(&tagIndex, "<li><a href=\"%s.html\">%s</a></li>\n", note.ID, template.HTMLEscapeString(note.Title))
+		}
+
+		tagIndex.WriteString("</ul>\n")
+	}
+
+	err = writePage("tags.html", sitePage{
+		Title:   "Tags",
+		Content: template.HTML(tagIndex.String()),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writePage("index.html", sitePage{
+		Title: root.Title,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writePage("search.html", sitePage{
+		Title:   "Search",
+		Content: template.HTML(`<input type="search" id="search" placeholder="Search"><ul id="results"></ul><script src="search-index.js"></script><script src="search.js"></script>`),
+	})
+	if err != nil {
+		return err
+	}
+
+	indexJSON, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "search-index.json"), indexJSON, 0644)
+	if err != nil {
+		return err
+	}
+
+	// Browsers refuse to fetch JSON from file:// URLs, so the index is also
+	// provided as a script.
+	err = os.WriteFile(filepath.Join(dir, "search-index.js"), []byte("var goplinSearchIndex = "+string(indexJSON)+";\n"), 0644)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "search.js"), []byte(siteSearchJS), 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, "style.css"), []byte(siteCSS), 0644)
+}
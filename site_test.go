@@ -0,0 +1,125 @@
+package goplin
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+)
+
+func TestNewSiteTree(t *testing.T) {
+	notebooks := []Notebook{
+		{ID: "root", Title: "Root"},
+		{ID: "b", ParentID: "root", Title: "beta"},
+		{ID: "a", ParentID: "root", Title: "Alpha"},
+		{ID: "a1", ParentID: "a", Title: "Inner"},
+		{ID: "other", Title: "Other"},
+		{ID: "o1", ParentID: "other", Title: "Not exported"},
+	}
+
+	notes := map[string][]Note{
+		"root": {{ID: "n1", Title: "Welcome"}},
+		"a":    {{ID: "n2", Title: "First"}, {ID: "n3", Title: "Second"}},
+		"a1":   {{ID: "n4", Title: "Deep"}},
+		"b":    nil,
+	}
+
+	tests := []struct {
+		name      string
+		rootID    string
+		wantTitle string
+		want      string
+	}{
+		{
+			name:      "notebook with sub-notebooks",
+			rootID:    "root",
+			wantTitle: "Root",
+			want: `<ul>
+<li><a href="n1.html">Welcome</a></li>
+<li><span class="notebook">Alpha</span><ul>
+<li><a href="n2.html">First</a></li>
+<li><a href="n3.html">Second</a></li>
+<li><span class="notebook">Inner</span><ul>
+<li><a href="n4.html">Deep</a></li>
+</ul></li>
+</ul></li>
+<li><span class="notebook">beta</span><ul>
+</ul></li>
+</ul>`,
+		},
+		{
+			name:      "sub-notebook as root",
+			rootID:    "a1",
+			wantTitle: "Inner",
+			want: `<ul>
+<li><a href="n4.html">Deep</a></li>
+</ul>`,
+		},
+	}
+
+	tmpl := template.Must(template.New("site").Parse(siteTemplate))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Only the notes of the root and its sub-notebooks are fetched.
+			treeNotes := make(map[string][]Note)
+
+			for _, id := range SubNotebookIDs(notebooks, tt.rootID) {
+				treeNotes[id] = notes[id]
+			}
+
+			tree := newSiteTree(notebooks, treeNotes, tt.rootID)
+
+			if tree.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", tree.Title, tt.wantTitle)
+			}
+
+			var buf bytes.Buffer
+
+			err := tmpl.ExecuteTemplate(&buf, "tree", tree)
+			if err != nil {
+				t.Fatalf("ExecuteTemplate: %v", err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("tree = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderNoteHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		note Note
+		want string
+	}{
+		{
+			name: "Markdown",
+			note: Note{Body: "# Title\n\n- [x] done\n\n[link](abc.html)"},
+			want: "<h1>Title</h1>\n<ul>\n<li><input checked=\"\" disabled=\"\" type=\"checkbox\"> done</li>\n</ul>\n<p><a href=\"abc.html\">link</a></p>\n",
+		},
+		{
+			name: "raw HTML is kept",
+			note: Note{Body: "<div class=\"x\">a</div>\n"},
+			want: "<div class=\"x\">a</div>\n",
+		},
+		{
+			name: "HTML note",
+			note: Note{Body: "<p>*not Markdown*</p>", MarkupLanguage: MarkupLanguageHTML},
+			want: "<p>*not Markdown*</p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderNoteHTML(tt.note)
+			if err != nil {
+				t.Fatalf("RenderNoteHTML: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("RenderNoteHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package goplin
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// UnreferencedResources is the notebook name under which the size of
+// resources not linked from any note is reported.
+const UnreferencedResources = "(unreferenced)"
+
+type NotebookResourceSize struct {
+	Notebook  string `json:"notebook"`
+	Resources int    `json:"resources"`
+	Size      int64  `json:"size"`
+}
+
+type TagNoteCount struct {
+	Tag   string `json:"tag"`
+	Notes int    `json:"notes"`
+}
+
+type MonthNoteCount struct {
+	Month string `json:"month"`
+	Notes int    `json:"notes"`
+}
+
+type NoteStats struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Notebook    string    `json:"notebook"`
+	Words       int       `json:"words"`
+	Characters  int       `json:"characters"`
+	UpdatedTime time.Time `json:"updated_time"`
+}
+
+// Stats is a report about the notes, notebooks, tags and resources.
+type Stats struct {
+	Notes           int                    `json:"notes"`
+	Notebooks       int                    `json:"notebooks"`
+	Tags            int                    `json:"tags"`
+	Resources       int                    `json:"resources"`
+	Words           int                    `json:"words"`
+	Characters      int                    `json:"characters"`
+	ResourceSize    int64                  `json:"resource_size"`
+	ResourceSizes   []NotebookResourceSize `json:"resource_size_per_notebook"`
+	NotesPerTag     []TagNoteCount         `json:"notes_per_tag"`
+	CreatedPerMonth []MonthNoteCount       `json:"created_per_month"`
+	LargestNotes    []NoteStats            `json:"largest_notes"`
+	StalestNotes    []NoteStats            `json:"stalest_notes"`
+}
+
+func fromMillis(ms int) time.Time {
+	return time.UnixMilli(int64(ms))
+}
+
+// Stats collects the statistics. The lists of the largest and the stalest
+// notes contain up to top notes.
+func (c *Client) Stats(top int) (*Stats, error) {
+	if top < 0 {
+		top = 0
+	}
+
+	notes, err := c.GetAllNotes("id,parent_id,title,body,created_time,updated_time,user_created_time,user_updated_time", "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	notebooks, err := c.GetAllNotebooks("id,parent_id,title", "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := c.GetAllTags("title", "ASC")
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := c.GetAllResources("id,title,size", "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Stats{
+		Notes:     len(notes),
+		Notebooks: len(notebooks),
+		Tags:      len(tags),
+		Resources: len(resources),
+	}
+
+	paths := NotebookPaths(notebooks)
+
+	resourceSizes := make(map[string]int64, len(resources))
+	for _, resource := range resources {
+		resourceSizes[resource.ID] = int64(resource.Size)
+		s.ResourceSize += int64(resource.Size)
+	}
+
+	// A resource is counted once per notebook with notes linking to it.
+	notebookResources := make(map[string]map[string]bool)
+	referenced := make(map[string]bool)
+	months := make(map[string]int)
+
+	var noteStats []NoteStats
+
+	for _, note := range notes {
+		words := len(strings.Fields(note.Body))
+		characters := utf8.RuneCountInString(note.Body)
+
+		s.Words += words
+		s.Characters += characters
+
+		created, updated := note.UserCreatedTime, note.UserUpdatedTime
+		if created == 0 {
+			created = note.CreatedTime
+		}
+
+		if updated == 0 {
+			updated = note.UpdatedTime
+		}
+
+		months[fromMillis(created).Format("2006-01")]++
+
+		noteStats = append(noteStats, NoteStats{
+			ID:          note.ID,
+			Title:       note.Title,
+			Notebook:    paths[note.ParentID],
+			Words:       words,
+			Characters:  characters,
+			UpdatedTime: fromMillis(updated),
+		})
+
+		for _, link := range ParseLinks(note.ID, note.Body) {
+			if _, ok := resourceSizes[link.TargetID]; !ok {
+				continue
+			}
+
+			notebook := paths[note.ParentID]
+			if notebookResources[notebook] == nil {
+				notebookResources[notebook] = make(map[string]bool)
+			}
+
+			notebookResources[notebook][link.TargetID] = true
+			referenced[link.TargetID] = true
+		}
+	}
+
+	for id := range resourceSizes {
+		if !referenced[id] {
+			if notebookResources[UnreferencedResources] == nil {
+				notebookResources[UnreferencedResources] = make(map[string]bool)
+			}
+
+			notebookResources[UnreferencedResources][id] = true
+		}
+	}
+
+	for notebook, ids := range notebookResources {
+		size := NotebookResourceSize{
+			Notebook:  notebook,
+			Resources: len(ids),
+		}
+
+		for id := range ids {
+			size.Size += resourceSizes[id]
+		}
+
+		s.ResourceSizes = append(s.ResourceSizes, size)
+	}
+
+	sort.Slice(s.ResourceSizes, func(i, j int) bool {
+		if s.ResourceSizes[i].Size != s.ResourceSizes[j].Size {
+			return s.ResourceSizes[i].Size > s.ResourceSizes[j].Size
+		}
+
+		return s.ResourceSizes[i].Notebook < s.ResourceSizes[j].Notebook
+	})
+
+	tagNotes, err := c.GetNotesByTags(TagIDs(tags), "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	for i, tag := range tags {
+		s.NotesPerTag = append(s.NotesPerTag, TagNoteCount{Tag: tag.Title, Notes: len(tagNotes[i])})
+	}
+
+	sort.SliceStable(s.NotesPerTag, func(i, j int) bool {
+		return s.NotesPerTag[i].Notes > s.NotesPerTag[j].Notes
+	})
+
+	for month, count := range months {
+		s.CreatedPerMonth = append(s.CreatedPerMonth, MonthNoteCount{Month: month, Notes: count})
+	}
+
+	sort.Slice(s.CreatedPerMonth, func(i, j int) bool {
+		return s.CreatedPerMonth[i].Month < s.CreatedPerMonth[j].Month
+	})
+
+	sort.SliceStable(noteStats, func(i, j int) bool {
+		return noteStats[i].Characters > noteStats[j].Characters
+	})
+
+	s.LargestNotes = append([]NoteStats{}, noteStats[:minInt(top, len(noteStats))]...)
+
+	sort.SliceStable(noteStats, func(i, j int) bool {
+		return noteStats[i].UpdatedTime.Before(noteStats[j].UpdatedTime)
+	})
+
+	s.StalestNotes = append([]NoteStats{}, noteStats[:minInt(top, len(noteStats))]...)
+
+	return s, nil
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,211 @@
+package goplin
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateVar is a variable declared by a note template. Its value is passed
+// by the caller or prompted for.
+type TemplateVar struct {
+	Name    string `yaml:"name"`
+	Prompt  string `yaml:"prompt"`
+	Default string `yaml:"default"`
+}
+
+// NoteTemplate is a note template. It consists of an optional YAML header,
+// enclosed in '---' lines, followed by the body. Title and body are
+// text/template templates.
+type NoteTemplate struct {
+	Title    string        `yaml:"title"`
+	Notebook string        `yaml:"notebook"`
+	Tags     []string      `yaml:"tags"`
+	Vars     []TemplateVar `yaml:"vars"`
+	Body     string        `yaml:"-"`
+}
+
+// ParseNoteTemplate parses the content of a template file.
+func ParseNoteTemplate(content string) (*NoteTemplate, error) {
+	var t NoteTemplate
+
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	if strings.HasPrefix(content, "---\n") {
+		header, body, found := strings.Cut(strings.TrimPrefix(content, "---\n"), "\n---\n")
+		if !found {
+			return nil, fmt.Errorf("template header is not terminated by '---'")
+		}
+
+		err := yaml.Unmarshal([]byte(header), &t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template header: %w", err)
+		}
+
+		content = body
+	}
+
+	t.Body = content
+
+	return &t, nil
+}
+
+// ReadNoteTemplate reads the template with the given name from dir. The name
+// may be given with or without the '.md' extension.
+func ReadNoteTemplate(dir string, name string) (*NoteTemplate, error) {
+	filename := strings.TrimSuffix(dir, "/") + "/" + name
+	if !strings.HasSuffix(name, ".md") {
+		filename += ".md"
+	}
+
+	content, err := ExpandBody("@" + filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read template '%s': %w", name, err)
+	}
+
+	return ParseNoteTemplate(content)
+}
+
+func newUUID() string {
+	id := []byte(NewItemID())
+
+	// Version 4, variant 1.
+	id[12] = '4'
+	id[16] = "89ab"[strings.IndexByte("0123456789abcdef", id[16])%4]
+
+	return fmt.Sprintf("%s-%s-%s-%s-%s", id[0:8], id[8:12], id[12:16], id[16:20], id[20:32])
+}
+
+// TemplateFuncs returns the functions available in note templates:
+//
+//	now                 current time
+//	date LAYOUT         current time formatted with the Go layout
+//	addDays N TIME      TIME moved by N days
+//	format LAYOUT TIME  TIME formatted with the Go layout
+//	uuid                random UUID
+//	id                  random Joplin item ID
+//	env NAME            value of the environment variable
+//	var NAME            value of the template variable
+//	search QUERY        items matching the Joplin search query
+//	todos TAG           open to-dos with the given tag
+//	link ITEM           Markdown link to a note, e.g. from search or todos
+func (c *Client) TemplateFuncs(vars map[string]string) template.FuncMap {
+	return template.FuncMap{
+		"now": time.Now,
+		"date": func(layout string) string {
+			return time.Now().Format(layout)
+		},
+		"addDays": func(n int, t time.Time) time.Time {
+			return t.AddDate(0, 0, n)
+		},
+		"format": func(layout string, t time.Time) string {
+			return t.Format(layout)
+		},
+		"uuid": newUUID,
+		"id":   NewItemID,
+		"env":  os.Getenv,
+		"var": func(name string) (string, error) {
+			value, ok := vars[name]
+			if !ok {
+				return "", fmt.Errorf("undefined template variable '%s'", name)
+			}
+
+			return value, nil
+		},
+		"search": func(query string) ([]Item, error) {
+			return c.Search(query, "note", "id,parent_id,title")
+		},
+		"todos": func(tag string) ([]Item, error) {
+			return c.Search(fmt.Sprintf("tag:\"%s\" type:todo iscompleted:0", tag), "note", "id,parent_id,title")
+		},
+		"link": func(item Item) string {
+			return fmt.Sprintf("[%s](:/%s)", item.Title, item.ID)
+		},
+	}
+}
+
+// RenderTemplate executes the text/template text with the template functions
+// and the given variables, which are also available as '.Vars'.
+func (c *Client) RenderTemplate(name string, text string, vars map[string]string) (string, error) {
+	return c.renderTemplate(name, text, vars, map[string]interface{}{
+		"Vars": vars,
+	})
+}
+
+func (c *Client) renderTemplate(name string, text string, vars map[string]string, data interface{}) (string, error) {
+	t, err := template.New(name).Funcs(c.TemplateFuncs(vars)).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+
+	err = t.Execute(&buf, data)
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// MissingVars returns the declared variables which have no value in vars.
+func (t *NoteTemplate) MissingVars(vars map[string]string) []TemplateVar {
+	var missing []TemplateVar
+
+	for _, v := range t.Vars {
+		if _, ok := vars[v.Name]; !ok {
+			missing = append(missing, v)
+		}
+	}
+
+	return missing
+}
+
+// RenderNoteTemplate renders the title and the body of the template. If title
+// is empty, the title of the template header is used. The body can refer to
+// the title as '.Title' and to the variables as '.Vars'. Declared variables
+// without a value get their default value.
+func (c *Client) RenderNoteTemplate(t *NoteTemplate, title string, vars map[string]string) (string, string, error) {
+	values := make(map[string]string, len(vars))
+
+	for _, v := range t.Vars {
+		values[v.Name] = v.Default
+	}
+
+	for name, value := range vars {
+		values[name] = value
+	}
+
+	data := map[string]interface{}{
+		"Vars": values,
+	}
+
+	if len(title) == 0 {
+		var err error
+
+		title, err = c.renderTemplate("title", t.Title, values, data)
+		if err != nil {
+			return "", "", err
+		}
+
+		title = strings.TrimSpace(title)
+	}
+
+	if len(title) == 0 {
+		return "", "", fmt.Errorf("no title given and the template does not define one")
+	}
+
+	data["Title"] = title
+
+	body, err := c.renderTemplate("body", t.Body, values, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	return title, strings.TrimLeft(body, "\n"), nil
+}